@@ -27,6 +27,7 @@ func tip() {
 func init() {
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(genCmd)
+	rootCmd.AddCommand(i18nCmd)
 }
 
 func ExecCmd() {
@@ -34,17 +34,7 @@ func init() {
 }
 
 func genCode(cmd *cobra.Command, args []string) {
-	inputs := yamlFiles
-	if cmd != nil {
-		if !cmd.Flags().Changed("yaml") {
-			inputs = nil
-		}
-	}
-	if len(args) > 0 {
-		inputs = append(inputs, args...)
-	}
-
-	files, err := collectYamlFiles(inputs)
+	files, err := collectYamlFiles(inputFiles(cmd, "yaml", yamlFiles, args))
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -54,6 +44,20 @@ func genCode(cmd *cobra.Command, args []string) {
 	}
 }
 
+// inputFiles merges the files given by a flag with positional args
+func inputFiles(cmd *cobra.Command, flag string, flagFiles []string, args []string) []string {
+	inputs := flagFiles
+	if cmd != nil {
+		if !cmd.Flags().Changed(flag) {
+			inputs = nil
+		}
+	}
+	if len(args) > 0 {
+		inputs = append(inputs, args...)
+	}
+	return inputs
+}
+
 func collectYamlFiles(inputs []string) ([]string, error) {
 	var out []string
 	seen := make(map[string]struct{})
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/kalifun/glitch/repo/generator"
+	"github.com/kalifun/glitch/repo/i18n"
+	"github.com/spf13/cobra"
+)
+
+var i18nCmd = &cobra.Command{
+	Use:     "i18n",
+	Short:   "Manage error message translations",
+	Long:    "Manage error message translations",
+	Example: "glitch i18n coverage -y errors",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var coverageCmd = &cobra.Command{
+	Use:     "coverage",
+	Short:   "Report translation coverage per language",
+	Long:    "Report translation coverage per language and exit non-zero when a language is below the threshold",
+	Example: "glitch i18n coverage -y errors --lang en,cn,fr --min 90",
+	Run: func(cmd *cobra.Command, args []string) {
+		coverage(cmd, args)
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:     "export",
+	Short:   "Export messages as gettext PO or XLIFF files",
+	Long:    "Export messages as gettext PO or XLIFF files, one file per language",
+	Example: "glitch i18n export -y errors --format po --lang fr,de --out translations",
+	Run: func(cmd *cobra.Command, args []string) {
+		export(cmd, args)
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:     "import [translation files]",
+	Short:   "Merge translated PO or XLIFF files into the yaml sources",
+	Long:    "Merge translated PO or XLIFF files into the yaml sources, preserving comments and ordering",
+	Example: "glitch i18n import -y errors translations/fr.po translations/de.xliff",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		importTranslations(cmd, args)
+	},
+}
+
+var i18nYamlFiles []string
+var coverageLangs []string
+var coverageMin float64
+var exportFormat string
+var exportLangs []string
+var exportSource string
+var exportOut string
+
+func init() {
+	i18nCmd.PersistentFlags().StringSliceVarP(&i18nYamlFiles, "yaml", "y", []string{"errors.yaml"}, "yaml file(s), directory, or glob")
+	coverageCmd.Flags().StringSliceVarP(&coverageLangs, "lang", "l", nil, "languages to report (defaults to every language found)")
+	coverageCmd.Flags().Float64Var(&coverageMin, "min", 100, "minimum coverage percentage per language")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "po", "output format: po or xliff")
+	exportCmd.Flags().StringSliceVarP(&exportLangs, "lang", "l", nil, "languages to export (defaults to every language found)")
+	exportCmd.Flags().StringVarP(&exportSource, "source", "s", "en", "source language")
+	exportCmd.Flags().StringVarP(&exportOut, "out", "o", ".", "output directory")
+	i18nCmd.AddCommand(coverageCmd, exportCmd, importCmd)
+}
+
+func loadI18nSources(cmd *cobra.Command, args []string) []generator.ErrorDesc {
+	files, err := collectYamlFiles(inputFiles(cmd, "yaml", i18nYamlFiles, args))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	descs, err := generator.LoadFiles(files)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	return descs
+}
+
+func coverage(cmd *cobra.Command, args []string) {
+	descs := loadI18nSources(cmd, args)
+	report := i18n.Coverage(descs, coverageLangs)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LANGUAGE\tCOVERAGE\tTRANSLATED\tMISSING")
+	failed := false
+	for _, c := range report {
+		fmt.Fprintf(w, "%s\t%.1f%%\t%d/%d\t%d\n", c.Language, c.Percent(), c.Translated, c.Total, len(c.Missing))
+		if c.Percent() < coverageMin {
+			failed = true
+		}
+	}
+	w.Flush()
+
+	for _, c := range report {
+		if len(c.Missing) == 0 {
+			continue
+		}
+		fmt.Printf("\nMissing %s:\n", c.Language)
+		for _, key := range c.Missing {
+			fmt.Printf("  - %s\n", key)
+		}
+	}
+
+	if failed {
+		fmt.Fprintf(os.Stderr, "\ncoverage below %.1f%%\n", coverageMin)
+		os.Exit(1)
+	}
+}
+
+func export(cmd *cobra.Command, args []string) {
+	var write func(io.Writer, i18n.Catalog) error
+	var ext string
+	switch exportFormat {
+	case "po":
+		write, ext = i18n.WritePO, ".po"
+	case "xliff", "xlf":
+		write, ext = i18n.WriteXLIFF, ".xliff"
+	default:
+		log.Fatalf("unsupported format %q, expected po or xliff", exportFormat)
+	}
+
+	descs := loadI18nSources(cmd, args)
+	langs := exportLangs
+	if len(langs) == 0 {
+		langs = i18n.Languages(descs)
+	}
+
+	if err := os.MkdirAll(exportOut, 0o755); err != nil {
+		log.Fatalln(err)
+	}
+	for _, lang := range langs {
+		if lang == exportSource {
+			continue
+		}
+		path := filepath.Join(exportOut, lang+ext)
+		f, err := os.Create(path)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if err := write(f, i18n.BuildCatalog(descs, exportSource, lang)); err != nil {
+			f.Close()
+			log.Fatalln(err)
+		}
+		if err := f.Close(); err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println(path)
+	}
+}
+
+func importTranslations(cmd *cobra.Command, args []string) {
+	files, err := collectYamlFiles(i18nYamlFiles)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if len(files) == 0 {
+		log.Fatalln("no yaml files provided")
+	}
+
+	catalogs := make([]i18n.Catalog, 0, len(args))
+	for _, path := range args {
+		c, err := i18n.ReadCatalogFile(path)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		catalogs = append(catalogs, c)
+	}
+
+	results, err := i18n.Merge(files, catalogs)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	for _, r := range results {
+		if r.Updated > 0 {
+			fmt.Printf("%s: %d message(s) updated\n", r.File, r.Updated)
+		}
+	}
+}
@@ -25,14 +25,41 @@ var permission_deniedErr = gerr.ErrWrapper{
 		"cn": "权限不足",
 	},
 	Description: "Permission denied",
+	HTTPStatus:  403,
+	GRPCCode:    "PERMISSION_DENIED",
+}
+
+var login_attempts_exceededErr = gerr.ErrWrapper{
+	Key:      "login_attempts_exceeded",
+	Code:     "AUTH_LOGIN_ATTEMPTS_EXCEEDED",
+	Category: "auth",
+	Severity: gerr.SeverityWarning,
+	Messages: map[string]string{
+		"en": "%d failed login attempts, account locked",
+		"cn": "登录失败 %d 次，账户已锁定",
+	},
+	Description: "Too many failed login attempts",
+	Plurals: map[string]map[string]string{
+		"en": {
+			"one":   "%d failed login attempt, account locked",
+			"other": "%d failed login attempts, account locked",
+		},
+	},
+	PluralArg: "1",
 }
 
 // InvalidToken represents Invalid auth token
+// It is a shared template: Args, With and Code return new instances
 var InvalidToken = gerr.NewError(invalid_tokenErr)
 
 // PermissionDenied represents Permission denied
+// It is a shared template: Args, With and Code return new instances
 var PermissionDenied = gerr.NewError(permission_deniedErr)
 
+// LoginAttemptsExceeded represents Too many failed login attempts
+// It is a shared template: Args, With and Code return new instances
+var LoginAttemptsExceeded = gerr.NewError(login_attempts_exceededErr)
+
 func init() {
 	if err := gerr.Register(invalid_tokenErr); err != nil {
 		panic(err)
@@ -40,6 +67,9 @@ func init() {
 	if err := gerr.Register(permission_deniedErr); err != nil {
 		panic(err)
 	}
+	if err := gerr.Register(login_attempts_exceededErr); err != nil {
+		panic(err)
+	}
 }
 
 // NewInvalidTokenWithMetadata creates a invalid_token error with metadata
@@ -51,3 +81,24 @@ func NewInvalidTokenWithMetadata(key string, value interface{}) *gerr.Error {
 func NewPermissionDeniedWithMetadata(key string, value interface{}) *gerr.Error {
 	return PermissionDenied.With(key, value)
 }
+
+// LoginAttemptsExceededF indicates this error requires format arguments
+// Usage: LoginAttemptsExceededF.Args("ErrMessage")
+var LoginAttemptsExceededF = LoginAttemptsExceeded
+
+// NewLoginAttemptsExceeded creates a login_attempts_exceeded error from its message arguments
+func NewLoginAttemptsExceeded(arg1 int) *gerr.Error {
+	return LoginAttemptsExceeded.Args(arg1)
+}
+
+// NewLoginAttemptsExceededWithArgs creates a login_attempts_exceeded error with arguments
+//
+// Deprecated: use NewLoginAttemptsExceeded, which checks the argument count and types
+func NewLoginAttemptsExceededWithArgs(args ...interface{}) *gerr.Error {
+	return LoginAttemptsExceeded.Args(args...)
+}
+
+// NewLoginAttemptsExceededWithMetadata creates a login_attempts_exceeded error with metadata
+func NewLoginAttemptsExceededWithMetadata(key string, value interface{}) *gerr.Error {
+	return LoginAttemptsExceeded.With(key, value)
+}
@@ -1,7 +1,11 @@
 // Code generated by glitch. DO NOT EDIT!
 package generated
 
-import "github.com/kalifun/glitch/repo/gerr"
+import (
+	"time"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)
 
 var order_not_foundErr = gerr.ErrWrapper{
 	Key:      "order_not_found",
@@ -12,7 +16,11 @@ var order_not_foundErr = gerr.ErrWrapper{
 		"en": "Order not found: %s",
 		"cn": "订单未找到: %s",
 	},
-	Description: "Order not found",
+	Description:     "Order not found",
+	InternalMessage: "no row in orders for id %s",
+	MetadataVisibility: map[string]gerr.Visibility{
+		"order_id": gerr.VisibilityPublic,
+	},
 }
 
 var invalid_order_stateErr = gerr.ErrWrapper{
@@ -27,12 +35,50 @@ var invalid_order_stateErr = gerr.ErrWrapper{
 	Description: "Invalid order state",
 }
 
+var order_service_busyErr = gerr.ErrWrapper{
+	Key:      "order_service_busy",
+	Code:     "ORDER_SERVICE_BUSY",
+	Category: "system",
+	Severity: gerr.SeverityWarning,
+	Messages: map[string]string{
+		"en": "Order service is busy, please retry later",
+		"cn": "订单服务繁忙，请稍后重试",
+	},
+	Description: "Order service busy",
+	HTTPStatus:  503,
+	Retryable:   true,
+	RetryAfter:  30 * time.Second,
+}
+
+var order_limit_exceededErr = gerr.ErrWrapper{
+	Key:      "order_limit_exceeded",
+	Code:     "ORDER_LIMIT_EXCEEDED",
+	Category: "business",
+	Severity: gerr.SeverityError,
+	Messages: map[string]string{
+		"en": "{count, plural, one {# item} other {# items}} in your cart exceed the limit of {limit, number, ::currency/USD}",
+		"cn": "购物车中的 {count} 件商品超出了 {limit, number, ::currency/USD} 的限额",
+	},
+	Description:   "Order limit exceeded",
+	MessageFormat: gerr.MessageFormatICU,
+}
+
 // OrderNotFound represents Order not found
+// It is a shared template: Args, With and Code return new instances
 var OrderNotFound = gerr.NewError(order_not_foundErr)
 
 // InvalidOrderState represents Invalid order state
+// It is a shared template: Args, With and Code return new instances
 var InvalidOrderState = gerr.NewError(invalid_order_stateErr)
 
+// OrderServiceBusy represents Order service busy
+// It is a shared template: Args, With and Code return new instances
+var OrderServiceBusy = gerr.NewError(order_service_busyErr)
+
+// OrderLimitExceeded represents Order limit exceeded
+// It is a shared template: Args, With and Code return new instances
+var OrderLimitExceeded = gerr.NewError(order_limit_exceededErr)
+
 func init() {
 	if err := gerr.Register(order_not_foundErr); err != nil {
 		panic(err)
@@ -40,13 +86,26 @@ func init() {
 	if err := gerr.Register(invalid_order_stateErr); err != nil {
 		panic(err)
 	}
+	if err := gerr.Register(order_service_busyErr); err != nil {
+		panic(err)
+	}
+	if err := gerr.Register(order_limit_exceededErr); err != nil {
+		panic(err)
+	}
 }
 
 // OrderNotFoundF indicates this error requires format arguments
 // Usage: OrderNotFoundF.Args("ErrMessage")
 var OrderNotFoundF = OrderNotFound
 
+// NewOrderNotFound creates a order_not_found error from its message arguments
+func NewOrderNotFound(arg1 string) *gerr.Error {
+	return OrderNotFound.Args(arg1)
+}
+
 // NewOrderNotFoundWithArgs creates a order_not_found error with arguments
+//
+// Deprecated: use NewOrderNotFound, which checks the argument count and types
 func NewOrderNotFoundWithArgs(args ...interface{}) *gerr.Error {
 	return OrderNotFound.Args(args...)
 }
@@ -60,7 +119,14 @@ func NewOrderNotFoundWithMetadata(key string, value interface{}) *gerr.Error {
 // Usage: InvalidOrderStateF.Args("ErrMessage")
 var InvalidOrderStateF = InvalidOrderState
 
+// NewInvalidOrderState creates a invalid_order_state error from its message arguments
+func NewInvalidOrderState(arg1 string) *gerr.Error {
+	return InvalidOrderState.Args(arg1)
+}
+
 // NewInvalidOrderStateWithArgs creates a invalid_order_state error with arguments
+//
+// Deprecated: use NewInvalidOrderState, which checks the argument count and types
 func NewInvalidOrderStateWithArgs(args ...interface{}) *gerr.Error {
 	return InvalidOrderState.Args(args...)
 }
@@ -69,3 +135,24 @@ func NewInvalidOrderStateWithArgs(args ...interface{}) *gerr.Error {
 func NewInvalidOrderStateWithMetadata(key string, value interface{}) *gerr.Error {
 	return InvalidOrderState.With(key, value)
 }
+
+// NewOrderServiceBusyWithMetadata creates a order_service_busy error with metadata
+func NewOrderServiceBusyWithMetadata(key string, value interface{}) *gerr.Error {
+	return OrderServiceBusy.With(key, value)
+}
+
+// NewOrderLimitExceeded creates a order_limit_exceeded error from its message arguments
+func NewOrderLimitExceeded(count int, limit float64) *gerr.Error {
+	return OrderLimitExceeded.Params(map[string]interface{}{"count": count, "limit": limit})
+}
+
+// NewOrderLimitExceededWithParams creates a order_limit_exceeded error with named placeholder values
+// Placeholders: count, limit
+func NewOrderLimitExceededWithParams(params map[string]interface{}) *gerr.Error {
+	return OrderLimitExceeded.Params(params)
+}
+
+// NewOrderLimitExceededWithMetadata creates a order_limit_exceeded error with metadata
+func NewOrderLimitExceededWithMetadata(key string, value interface{}) *gerr.Error {
+	return OrderLimitExceeded.With(key, value)
+}
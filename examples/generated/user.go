@@ -28,9 +28,11 @@ var invalid_emailErr = gerr.ErrWrapper{
 }
 
 // UserNotFound represents User not found
+// It is a shared template: Args, With and Code return new instances
 var UserNotFound = gerr.NewError(user_not_foundErr)
 
 // InvalidEmail represents Invalid email address
+// It is a shared template: Args, With and Code return new instances
 var InvalidEmail = gerr.NewError(invalid_emailErr)
 
 func init() {
@@ -46,7 +48,14 @@ func init() {
 // Usage: UserNotFoundF.Args("ErrMessage")
 var UserNotFoundF = UserNotFound
 
+// NewUserNotFound creates a user_not_found error from its message arguments
+func NewUserNotFound(userID string) *gerr.Error {
+	return UserNotFound.Args(userID)
+}
+
 // NewUserNotFoundWithArgs creates a user_not_found error with arguments
+//
+// Deprecated: use NewUserNotFound, which checks the argument count and types
 func NewUserNotFoundWithArgs(args ...interface{}) *gerr.Error {
 	return UserNotFound.Args(args...)
 }
@@ -60,7 +69,14 @@ func NewUserNotFoundWithMetadata(key string, value interface{}) *gerr.Error {
 // Usage: InvalidEmailF.Args("ErrMessage")
 var InvalidEmailF = InvalidEmail
 
+// NewInvalidEmail creates a invalid_email error from its message arguments
+func NewInvalidEmail(arg1 string) *gerr.Error {
+	return InvalidEmail.Args(arg1)
+}
+
 // NewInvalidEmailWithArgs creates a invalid_email error with arguments
+//
+// Deprecated: use NewInvalidEmail, which checks the argument count and types
 func NewInvalidEmailWithArgs(args ...interface{}) *gerr.Error {
 	return InvalidEmail.Args(args...)
 }
@@ -1,8 +1,10 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -10,6 +12,11 @@ import (
 const (
 	Pack       = "package %s"
 	Import     = `import "github.com/kalifun/glitch/repo/gerr"`
+	ImportTime = `import (
+	"time"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)`
 	Declare    = "// Code generated by glitch. DO NOT EDIT!"
 	DeclareErr = `var %s = gerr.ErrWrapper{
 	Key:      "%s",
@@ -19,7 +26,7 @@ const (
 	Messages: map[string]string{
 %s	},
 	Description: "%s",
-}`
+%s}`
 	RegisterCall = `	if err := gerr.Register(%s); err != nil {
 		panic(err)
 	}`
@@ -52,19 +59,19 @@ func (c *codeGenerator) Exec() error {
 		return fmt.Errorf("no yaml files provided")
 	}
 
-	descs := make([]ErrorDesc, 0, len(c.file_paths))
-	for _, filePath := range c.file_paths {
-		desc, err := c.readFile(filePath)
-		if err != nil {
-			return err
-		}
-		descs = append(descs, desc)
+	descs, err := LoadFiles(c.file_paths)
+	if err != nil {
+		return err
 	}
 
 	if err := validateDuplicates(descs); err != nil {
 		return err
 	}
 
+	if err := validateItems(descs); err != nil {
+		return err
+	}
+
 	for i, filePath := range c.file_paths {
 		desc := descs[i]
 		outputFile := outputFileName(filePath, len(c.file_paths) == 1)
@@ -76,7 +83,11 @@ func (c *codeGenerator) Exec() error {
 		var s string
 		s += Declare + "\n"
 		s += fmt.Sprintf(Pack, c.package_name) + "\n"
-		s += Import + "\n"
+		if desc.needsTime() {
+			s += ImportTime + "\n"
+		} else {
+			s += Import + "\n"
+		}
 		s += desc.ToString()
 
 		data, err := formatCode(s)
@@ -96,7 +107,20 @@ func (c *codeGenerator) Exec() error {
 	return nil
 }
 
-func (c *codeGenerator) readFile(filePath string) (ErrorDesc, error) {
+// LoadFiles reads and parses the given YAML definition files in order
+func LoadFiles(filePaths []string) ([]ErrorDesc, error) {
+	descs := make([]ErrorDesc, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		desc, err := readFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		descs = append(descs, desc)
+	}
+	return descs, nil
+}
+
+func readFile(filePath string) (ErrorDesc, error) {
 	v := viper.New()
 	v.SetConfigFile(filePath)
 	var desc ErrorDesc
@@ -111,6 +135,12 @@ func (c *codeGenerator) readFile(filePath string) (ErrorDesc, error) {
 	for i := range desc.Error {
 		desc.Error[i].SourceFile = filePath
 		desc.Error[i].Index = i
+		if desc.Error[i].MessageFormat == "" {
+			desc.Error[i].MessageFormat = desc.MessageFormat
+		}
+		if err := desc.Error[i].normalizeMessages(); err != nil {
+			return desc, err
+		}
 	}
 	return desc, nil
 }
@@ -139,7 +169,86 @@ func validateDuplicates(descs []ErrorDesc) error {
 		}
 	}
 	if len(errs) > 0 {
-		return fmt.Errorf(strings.Join(errs, "\n"))
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func validateItems(descs []ErrorDesc) error {
+	var errs []string
+
+	for _, desc := range descs {
+		for _, item := range desc.Error {
+			if item.HTTPStatus != 0 && (item.HTTPStatus < 100 || item.HTTPStatus > 599) {
+				errs = append(errs, fmt.Sprintf("invalid http_status %d: %s", item.HTTPStatus, errorLoc(item)))
+			}
+			if item.GRPCCode != "" && !isGRPCCode(item.GRPCCode) {
+				errs = append(errs, fmt.Sprintf("invalid grpc_code %q: %s", item.GRPCCode, errorLoc(item)))
+			}
+			if err := validateMessageFormat(item.MessageFormat); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", err, errorLoc(item)))
+			} else if item.isICU() {
+				for _, err := range validateICU(item) {
+					errs = append(errs, fmt.Sprintf("%s: %s", err, errorLoc(item)))
+				}
+			} else {
+				if _, err := formatArgs(item); err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %s", err, errorLoc(item)))
+				}
+				if err := validatePlaceholders(item); err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %s", err, errorLoc(item)))
+				}
+				for _, err := range validatePlurals(item) {
+					errs = append(errs, fmt.Sprintf("%s: %s", err, errorLoc(item)))
+				}
+			}
+			if err := validateInternalMessage(item); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", err, errorLoc(item)))
+			}
+			if err := validateVisibility(item); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", err, errorLoc(item)))
+			}
+			if item.RetryAfter != "" {
+				if d, err := time.ParseDuration(item.RetryAfter); err != nil || d <= 0 {
+					errs = append(errs, fmt.Sprintf("invalid retry_after %q: %s", item.RetryAfter, errorLoc(item)))
+				} else if !item.Retryable {
+					errs = append(errs, fmt.Sprintf("retry_after requires retryable: true: %s", errorLoc(item)))
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// validatePlaceholders checks that every language of item uses the same
+// named placeholders. The "other" plural form stands for its language; the
+// other forms may use a subset of its placeholders.
+func validatePlaceholders(item ErrorItem) error {
+	var mismatches []string
+	var first string
+	var want []string
+	for i, lang := range sortedLanguages(item.Message) {
+		got := placeholders(item.Message[lang])
+		if i == 0 {
+			first, want = lang, got
+		} else if strings.Join(got, ",") != strings.Join(want, ",") {
+			mismatches = append(mismatches, fmt.Sprintf("%s {%s} vs %s {%s}", first, strings.Join(want, ", "), lang, strings.Join(got, ", ")))
+		}
+
+		forms := item.Plurals[lang]
+		for _, form := range sortedLanguages(forms) {
+			for _, name := range placeholders(forms[form]) {
+				if !contains(got, name) {
+					mismatches = append(mismatches, fmt.Sprintf("%s/%s uses {%s} missing from %s/other", lang, form, name, lang))
+				}
+			}
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("placeholders differ between languages: %s", strings.Join(mismatches, "; "))
 	}
 	return nil
 }
@@ -2,24 +2,39 @@ package generator
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kalifun/glitch/utils"
 )
 
 type ErrorItem struct {
-	Key         string            `yaml:"key"`
-	Code        string            `yaml:"code"`
-	Category    string            `yaml:"category,omitempty"`
-	Severity    string            `yaml:"severity,omitempty"`
-	Description string            `yaml:"description,omitempty"`
-	Message     map[string]string `yaml:"message,omitempty"`
-	SourceFile  string            `yaml:"-"`
-	Index       int               `yaml:"-"`
+	Key             string                       `yaml:"key"`
+	Code            string                       `yaml:"code"`
+	Category        string                       `yaml:"category,omitempty"`
+	Severity        string                       `yaml:"severity,omitempty"`
+	Description     string                       `yaml:"description,omitempty"`
+	RawMessage      map[string]interface{}       `yaml:"message,omitempty" mapstructure:"message"`
+	Message         map[string]string            `yaml:"-" mapstructure:"-"`
+	InternalMessage string                       `yaml:"internal_message,omitempty" mapstructure:"internal_message"`
+	Plurals         map[string]map[string]string `yaml:"-" mapstructure:"-"`
+	PluralArg       string                       `yaml:"plural_arg,omitempty" mapstructure:"plural_arg"`
+	MessageFormat   string                       `yaml:"message_format,omitempty" mapstructure:"message_format"`
+	HTTPStatus      int                          `yaml:"http_status,omitempty" mapstructure:"http_status"`
+	GRPCCode        string                       `yaml:"grpc_code,omitempty" mapstructure:"grpc_code"`
+	Retryable       bool                         `yaml:"retryable,omitempty"`
+	RetryAfter      string                       `yaml:"retry_after,omitempty" mapstructure:"retry_after"`
+	Params          []ParamItem                  `yaml:"params,omitempty"`
+	PublicMetadata  []string                     `yaml:"public_metadata,omitempty" mapstructure:"public_metadata"`
+	PrivateMetadata []string                     `yaml:"private_metadata,omitempty" mapstructure:"private_metadata"`
+	SourceFile      string                       `yaml:"-"`
+	Index           int                          `yaml:"-"`
 }
 
 type ErrorDesc struct {
-	Error []ErrorItem `yaml:"error"`
+	MessageFormat string      `yaml:"message_format,omitempty" mapstructure:"message_format"`
+	Error         []ErrorItem `yaml:"error"`
 }
 
 func (e ErrorDesc) ToString() string {
@@ -69,15 +84,45 @@ func (e ErrorDesc) ToString() string {
 			description = utils.FirstUpper(strings.ReplaceAll(v.Key, "_", " "))
 		}
 
+		// Optional fields are only emitted when set
+		var extraLines []string
+		if v.HTTPStatus != 0 {
+			extraLines = append(extraLines, fmt.Sprintf("\tHTTPStatus: %d,\n", v.HTTPStatus))
+		}
+		if v.GRPCCode != "" {
+			extraLines = append(extraLines, fmt.Sprintf("\tGRPCCode: \"%s\",\n", strings.ToUpper(v.GRPCCode)))
+		}
+		if v.Retryable {
+			extraLines = append(extraLines, "\tRetryable: true,\n")
+		}
+		if d, err := time.ParseDuration(v.RetryAfter); err == nil && d > 0 {
+			extraLines = append(extraLines, fmt.Sprintf("\tRetryAfter: %s,\n", goDuration(d)))
+		}
+		if len(v.Plurals) > 0 {
+			extraLines = append(extraLines, fmt.Sprintf("\tPlurals: %s,\n", pluralsLiteral(v.Plurals)))
+			extraLines = append(extraLines, fmt.Sprintf("\tPluralArg: \"%s\",\n", utils.EscapeString(v.PluralArg)))
+		}
+		if v.isICU() {
+			extraLines = append(extraLines, "\tMessageFormat: gerr.MessageFormatICU,\n")
+		}
+		if v.InternalMessage != "" {
+			extraLines = append(extraLines, fmt.Sprintf("\tInternalMessage: \"%s\",\n", utils.EscapeString(v.InternalMessage)))
+		}
+		if len(v.PublicMetadata) > 0 || len(v.PrivateMetadata) > 0 {
+			extraLines = append(extraLines, fmt.Sprintf("\tMetadataVisibility: %s,\n", visibilityLiteral(v.PublicMetadata, v.PrivateMetadata)))
+		}
+		extras := strings.Join(extraLines, "")
+
 		low := utils.FirstLower(v.Key)
 		upper := utils.FirstUpper(utils.ToCamelCase(v.Key))
 
 		// Generate error definition (private)
-		defStr := fmt.Sprintf(DeclareErr, low+"Err", v.Key, v.Code, category, severity, messagesStr, description)
+		defStr := fmt.Sprintf(DeclareErr, low+"Err", v.Key, v.Code, category, severity, messagesStr, description, extras)
 		definitions = append(definitions, defStr+"\n")
 
-		// Generate public error variable that can be used directly
-		varStr := fmt.Sprintf("// %s represents %s\nvar %s = gerr.NewError(%sErr)",
+		// Generate public error template; Args, With and Code always return
+		// new instances so the template itself is never mutated
+		varStr := fmt.Sprintf("// %s represents %s\n// It is a shared template: Args, With and Code return new instances\nvar %s = gerr.NewError(%sErr)",
 			upper, description, upper, low)
 		variables = append(variables, varStr+"\n")
 
@@ -85,20 +130,51 @@ func (e ErrorDesc) ToString() string {
 		inits = append(inits, fmt.Sprintf(RegisterCall, low+"Err")+"\n")
 
 		// Generate F suffix variable and helpers for errors that need formatting
-		hasArgs := e.hasFormatArgs(messages)
-		if hasArgs {
+		// Arguments were validated before generation
+		args, _ := formatArgs(v)
+		if len(args) > 0 && v.isICU() {
+			// ICU messages take named arguments as params
+			params := make([]string, len(args))
+			for i, arg := range args {
+				params[i] = arg.Name + " " + arg.Type
+			}
+			typedComment := fmt.Sprintf("// New%s creates a %s error from its message arguments", upper, v.Key)
+			typedFunc := fmt.Sprintf("func New%s(%s) *gerr.Error {\n\treturn %s\n}",
+				upper, strings.Join(params, ", "), icuConstructorBody(upper, args))
+			helpers = append(helpers, typedComment+"\n"+typedFunc+"\n")
+		} else if len(args) > 0 {
 			// Generate F suffix variable with clear documentation
 			formatVar := fmt.Sprintf("// %sF indicates this error requires format arguments\n// Usage: %sF.Args(\"%s\")\nvar %sF = %s",
 				upper, upper, "ErrMessage", upper, upper)
 			helpers = append(helpers, formatVar+"\n")
 
-			// Generate helper function
-			helperComment := fmt.Sprintf("// New%sWithArgs creates a %s error with arguments", upper, v.Key)
+			// Generate typed constructor from the message format verbs
+			params := make([]string, len(args))
+			names := make([]string, len(args))
+			for i, arg := range args {
+				params[i] = arg.Name + " " + arg.Type
+				names[i] = arg.Name
+			}
+			typedComment := fmt.Sprintf("// New%s creates a %s error from its message arguments", upper, v.Key)
+			typedFunc := fmt.Sprintf("func New%s(%s) *gerr.Error {\n\treturn %s.Args(%s)\n}",
+				upper, strings.Join(params, ", "), upper, strings.Join(names, ", "))
+			helpers = append(helpers, typedComment+"\n"+typedFunc+"\n")
+
+			// Generate untyped helper function
+			helperComment := fmt.Sprintf("// New%sWithArgs creates a %s error with arguments\n//\n// Deprecated: use New%s, which checks the argument count and types", upper, v.Key, upper)
 			helperFunc := fmt.Sprintf("func New%sWithArgs(args ...interface{}) *gerr.Error {\n\treturn %s.Args(args...)\n}",
 				upper, upper)
 			helpers = append(helpers, helperComment+"\n"+helperFunc+"\n")
 		}
 
+		// Generate helper for messages with named placeholders
+		if params := v.placeholderNames(); len(params) > 0 {
+			paramsComment := fmt.Sprintf("// New%sWithParams creates a %s error with named placeholder values\n// Placeholders: %s", upper, v.Key, strings.Join(params, ", "))
+			paramsFunc := fmt.Sprintf("func New%sWithParams(params map[string]interface{}) *gerr.Error {\n\treturn %s.Params(params)\n}",
+				upper, upper)
+			helpers = append(helpers, paramsComment+"\n"+paramsFunc+"\n")
+		}
+
 		// Generate helper function for creating instances with metadata
 		helperComment2 := fmt.Sprintf("// New%sWithMetadata creates a %s error with metadata", upper, v.Key)
 		helperFunc2 := fmt.Sprintf("func New%sWithMetadata(key string, value interface{}) *gerr.Error {\n\treturn %s.With(key, value)\n}",
@@ -114,12 +190,39 @@ func (e ErrorDesc) ToString() string {
 	return result
 }
 
-// hasFormatArgs checks if any message contains format arguments
-func (e ErrorDesc) hasFormatArgs(messages map[string]string) bool {
-	for _, msg := range messages {
-		if ok := needsFormat(msg); ok {
-			return ok
+// needsTime reports whether the generated code uses the time package
+func (e ErrorDesc) needsTime() bool {
+	for _, v := range e.Error {
+		if d, err := time.ParseDuration(v.RetryAfter); err == nil && d > 0 {
+			return true
+		}
+		if v.isICU() {
+			args, _ := formatArgs(v)
+			for _, arg := range args {
+				if strings.Contains(arg.Type, "time.") {
+					return true
+				}
+			}
 		}
 	}
 	return false
 }
+
+// placeholderNames returns the named placeholders used by the messages
+func (item ErrorItem) placeholderNames() []string {
+	if item.isICU() {
+		args, _ := formatArgs(item)
+		var names []string
+		for _, arg := range args {
+			if _, err := strconv.Atoi(arg.Param); err != nil {
+				names = append(names, arg.Param)
+			}
+		}
+		return names
+	}
+	for _, msg := range item.messageVariants() {
+		// validation guarantees every message uses the same set
+		return placeholders(msg)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package generator
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+// icuTypes maps ICU argument types to the Go type of their value
+var icuTypes = map[string]string{
+	"":              anyType,
+	"number":        "float64",
+	"plural":        "int",
+	"selectordinal": "int",
+	"select":        "string",
+	"date":          "time.Time",
+	"time":          "time.Time",
+}
+
+// isICU reports whether the messages of the item use ICU MessageFormat
+func (item ErrorItem) isICU() bool {
+	return item.MessageFormat == gerr.MessageFormatICU
+}
+
+// validateMessageFormat checks the message_format declaration
+func validateMessageFormat(format string) error {
+	switch format {
+	case "", gerr.MessageFormatPrintf, gerr.MessageFormatICU:
+		return nil
+	}
+	return fmt.Errorf("invalid message_format %q, expected %s or %s", format, gerr.MessageFormatPrintf, gerr.MessageFormatICU)
+}
+
+// validateICU checks the ICU messages of an item
+func validateICU(item ErrorItem) []string {
+	var errs []string
+	if len(item.Plurals) > 0 {
+		errs = append(errs, "plural forms are not supported with message_format icu, use {n, plural, ...}")
+	}
+	if item.PluralArg != "" {
+		errs = append(errs, "plural_arg is not used with message_format icu")
+	}
+	if _, err := icuArgs(item); err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, lang := range sortedLanguages(item.Message) {
+		parsed, _ := gerr.ParseICUMessage(item.Message[lang])
+		for _, arg := range parsed {
+			if arg.Type == "number" && arg.Style == "currency" {
+				errs = append(errs, fmt.Sprintf("%s: argument %q needs a currency code, e.g. {%s, number, ::currency/USD}", lang, arg.Name, arg.Name))
+			}
+		}
+	}
+	return errs
+}
+
+// icuArgs derives the typed constructor arguments of an ICU item: numbered
+// arguments such as {0} first, then named arguments in order of first use.
+// It fails when a message does not parse or languages use different arguments.
+func icuArgs(item ErrorItem) ([]formatArg, error) {
+	langs := sortedLanguages(item.Message)
+
+	var order []string
+	types := make(map[string]string)
+	var problems []string
+	var first string
+	var want []string
+	for i, lang := range langs {
+		parsed, err := gerr.ParseICUMessage(item.Message[lang])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", lang, err)
+		}
+
+		names := make([]string, 0, len(parsed))
+		for _, arg := range parsed {
+			names = append(names, arg.Name)
+			typ, seen := types[arg.Name]
+			if !seen {
+				order = append(order, arg.Name)
+			}
+			merged, ok := mergeICUType(typ, icuTypes[arg.Type])
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: argument %q used as %s and %s", lang, arg.Name, typ, icuTypes[arg.Type]))
+				continue
+			}
+			types[arg.Name] = merged
+		}
+		sort.Strings(names)
+
+		if i == 0 {
+			first, want = lang, names
+			continue
+		}
+		if strings.Join(names, ",") != strings.Join(want, ",") {
+			problems = append(problems, fmt.Sprintf("%s {%s} vs %s {%s}", first, strings.Join(want, ", "), lang, strings.Join(names, ", ")))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("ICU arguments differ: %s", strings.Join(problems, "; "))
+	}
+
+	var positional, named []formatArg
+	for _, name := range order {
+		arg := formatArg{Name: name, Type: types[name], Param: name}
+		if index, err := strconv.Atoi(name); err == nil {
+			arg.Name = "arg" + name
+			arg.index = index
+			positional = append(positional, arg)
+			continue
+		}
+		if !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("argument %q is not a valid Go identifier", name)
+		}
+		named = append(named, arg)
+	}
+	sort.Slice(positional, func(i, j int) bool { return positional[i].index < positional[j].index })
+	for i, arg := range positional {
+		if arg.index != i {
+			return nil, fmt.Errorf("numbered arguments must start at {0} without gaps, found {%d}", arg.index)
+		}
+	}
+	args := append(positional, named...)
+
+	for _, p := range item.Params {
+		found := false
+		for i := range args {
+			if args[i].Param != p.Name {
+				continue
+			}
+			found = true
+			if p.Type != "" {
+				if _, err := parser.ParseExpr(p.Type); err != nil {
+					return nil, fmt.Errorf("param %q has invalid type %q", p.Name, p.Type)
+				}
+				args[i].Type = p.Type
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("param %q is not an argument of the messages", p.Name)
+		}
+	}
+	return args, nil
+}
+
+// sortedLanguages returns the languages of messages in order
+func sortedLanguages(messages map[string]string) []string {
+	langs := make([]string, 0, len(messages))
+	for lang := range messages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// mergeICUType combines the types of an argument used several times;
+// int widens to float64
+func mergeICUType(a, b string) (string, bool) {
+	if (a == "int" && b == "float64") || (a == "float64" && b == "int") {
+		return "float64", true
+	}
+	return mergeType(a, b)
+}
+
+// icuConstructorBody returns the expression building an ICU error from its
+// constructor arguments
+func icuConstructorBody(upper string, args []formatArg) string {
+	var positional, named []string
+	for _, arg := range args {
+		if _, err := strconv.Atoi(arg.Param); err == nil {
+			positional = append(positional, arg.Name)
+			continue
+		}
+		named = append(named, fmt.Sprintf("%q: %s", arg.Param, arg.Name))
+	}
+
+	body := upper
+	if len(positional) > 0 {
+		body += ".Args(" + strings.Join(positional, ", ") + ")"
+	}
+	if len(named) > 0 {
+		body += ".Params(map[string]interface{}{" + strings.Join(named, ", ") + "})"
+	}
+	return body
+}
@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"github.com/kalifun/glitch/utils"
+)
+
+// normalizeMessages splits the raw YAML messages into plain messages and
+// plural forms. A language maps either to a string or to its plural forms;
+// the "other" form doubles as the plain message of a plural language.
+func (item *ErrorItem) normalizeMessages() error {
+	item.Message = make(map[string]string, len(item.RawMessage))
+	for lang, raw := range item.RawMessage {
+		switch msg := raw.(type) {
+		case string:
+			item.Message[lang] = msg
+		case map[string]interface{}:
+			forms := make(map[string]string, len(msg))
+			for form, text := range msg {
+				s, ok := text.(string)
+				if !ok {
+					return fmt.Errorf("message %s.%s must be a string: %s", lang, form, errorLoc(*item))
+				}
+				forms[form] = s
+			}
+			if item.Plurals == nil {
+				item.Plurals = make(map[string]map[string]string)
+			}
+			item.Plurals[lang] = forms
+			item.Message[lang] = forms[gerr.PluralOther]
+		default:
+			return fmt.Errorf("message %s must be a string or plural forms: %s", lang, errorLoc(*item))
+		}
+	}
+	return nil
+}
+
+// messageVariants returns every message of the item keyed by language,
+// or by language/form for plural forms
+func (item ErrorItem) messageVariants() map[string]string {
+	result := make(map[string]string, len(item.Message))
+	for lang, msg := range item.Message {
+		forms, ok := item.Plurals[lang]
+		if !ok {
+			result[lang] = msg
+			continue
+		}
+		for form, text := range forms {
+			result[lang+"/"+form] = text
+		}
+	}
+	return result
+}
+
+// validatePlurals checks plural forms against the CLDR rules of each
+// language and the plural_arg declaration
+func validatePlurals(item ErrorItem) []string {
+	if len(item.Plurals) == 0 {
+		if item.PluralArg != "" {
+			return []string{"plural_arg is set but no message has plural forms"}
+		}
+		return nil
+	}
+
+	var errs []string
+	if item.PluralArg == "" {
+		errs = append(errs, "plural forms require plural_arg")
+	} else if index, err := strconv.Atoi(item.PluralArg); err == nil {
+		if args, argErr := formatArgs(item); argErr == nil && (index < 1 || index > len(args)) {
+			errs = append(errs, fmt.Sprintf("plural_arg %d is out of range, messages use %d arguments", index, len(args)))
+		}
+	} else if !contains(item.placeholderNames(), item.PluralArg) {
+		errs = append(errs, fmt.Sprintf("plural_arg %q is neither an argument index nor a placeholder", item.PluralArg))
+	}
+
+	langs := make([]string, 0, len(item.Message))
+	for lang := range item.Message {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	for _, lang := range langs {
+		required := gerr.PluralForms(lang)
+		forms, ok := item.Plurals[lang]
+		if !ok {
+			if len(required) > 1 {
+				errs = append(errs, fmt.Sprintf("%s needs plural forms %s", lang, strings.Join(required, ", ")))
+			}
+			continue
+		}
+
+		var missing, unknown []string
+		for _, form := range required {
+			if _, ok := forms[form]; !ok {
+				missing = append(missing, form)
+			}
+		}
+		for form := range forms {
+			if !gerr.IsPluralCategory(form) {
+				unknown = append(unknown, form)
+			}
+		}
+		sort.Strings(unknown)
+		if len(missing) > 0 {
+			errs = append(errs, fmt.Sprintf("%s is missing plural forms %s", lang, strings.Join(missing, ", ")))
+		}
+		if len(unknown) > 0 {
+			errs = append(errs, fmt.Sprintf("%s has unknown plural forms %s", lang, strings.Join(unknown, ", ")))
+		}
+	}
+	return errs
+}
+
+// pluralsLiteral renders the plural forms as a Go map literal
+func pluralsLiteral(plurals map[string]map[string]string) string {
+	langs := make([]string, 0, len(plurals))
+	for lang := range plurals {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	var b strings.Builder
+	b.WriteString("map[string]map[string]string{\n")
+	for _, lang := range langs {
+		fmt.Fprintf(&b, "\t\t%q: {\n", lang)
+		for _, form := range pluralOrder(plurals[lang]) {
+			fmt.Fprintf(&b, "\t\t\t\"%s\": \"%s\",\n", form, utils.EscapeString(plurals[lang][form]))
+		}
+		b.WriteString("\t\t},\n")
+	}
+	b.WriteString("\t}")
+	return b.String()
+}
+
+// pluralOrder returns the forms in canonical CLDR order
+func pluralOrder(forms map[string]string) []string {
+	var result []string
+	for _, form := range []string{gerr.PluralZero, gerr.PluralOne, gerr.PluralTwo, gerr.PluralFew, gerr.PluralMany, gerr.PluralOther} {
+		if _, ok := forms[form]; ok {
+			result = append(result, form)
+		}
+	}
+	return result
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,14 @@
 package generator
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"mvdan.cc/gofumpt/format"
 )
@@ -47,23 +50,59 @@ func sanitizeFileBase(name string) string {
 	return name
 }
 
-// auto check if string needs to be formatted
-// support %v, %s, %d, %f, %t, %b, %c, %o, %x, %X, %U, %e, %E, %f, %F, %g, %G, %p, %q, %x, %X, %U.
-func needsFormat(s string) bool {
-	// Skip the escaped %%
-	s = strings.ReplaceAll(s, "%%", "")
+// placeholderPattern matches named placeholders such as {user_id}
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
 
-	// Check the remaining %
-	for i := 0; i < len(s); i++ {
-		if s[i] == '%' {
-			if i+1 >= len(s) {
-				return false // Single % at the end
-			}
-			next := s[i+1]
-			if strings.ContainsRune("vTtbcdoqxXUeEfFgGsp", rune(next)) {
-				return true
-			}
+// placeholders returns the sorted, unique named placeholders of s
+func placeholders(s string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range placeholderPattern.FindAllStringSubmatch(s, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// grpcCodes lists the canonical gRPC status code names of errors; OK is
+// left out since an OK status carries no error
+var grpcCodes = []string{
+	"CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED",
+	"NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED",
+	"INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+// isGRPCCode reports whether s names a gRPC error code, e.g. NOT_FOUND
+func isGRPCCode(s string) bool {
+	s = strings.ToUpper(s)
+	for _, code := range grpcCodes {
+		if code == s {
+			return true
 		}
 	}
 	return false
 }
+
+// goDuration renders d as a Go expression such as 30 * time.Second
+func goDuration(d time.Duration) string {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", int64(d))
+}
@@ -0,0 +1,250 @@
+package generator
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+// ParamItem declares the name and Go type of a message format argument
+type ParamItem struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type,omitempty"`
+}
+
+// formatArg is an argument of a generated typed constructor
+type formatArg struct {
+	Name  string
+	Type  string
+	Param string // ICU argument name, empty for fmt verbs
+	index int    // position of a numbered ICU argument
+}
+
+// formatVerb is a verb of a format string and the argument it consumes
+type formatVerb struct {
+	index int // zero based argument index
+	verb  byte
+}
+
+// anyType is the Go type used for verbs that accept any value
+const anyType = "interface{}"
+
+// verbTypes maps fmt verbs to the Go type of their argument.
+// Verbs missing from the map accept any value.
+var verbTypes = map[byte]string{
+	's': "string",
+	'q': "string",
+	'd': "int",
+	'b': "int",
+	'o': "int",
+	'O': "int",
+	'c': "rune",
+	'U': "rune",
+	'e': "float64",
+	'E': "float64",
+	'f': "float64",
+	'F': "float64",
+	'g': "float64",
+	'G': "float64",
+	't': "bool",
+}
+
+// parseVerbs returns the verbs of a fmt format string in order,
+// resolving explicit argument indexes such as %[2]s
+func parseVerbs(s string) ([]formatVerb, error) {
+	var verbs []formatVerb
+	argNum := 0
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		i++
+		if i >= len(s) {
+			break
+		}
+		if s[i] == '%' {
+			continue
+		}
+
+		// flags
+		for i < len(s) && strings.IndexByte("+-# 0", s[i]) >= 0 {
+			i++
+		}
+		// argument index, width, precision and a second argument index
+		for i < len(s) && strings.IndexByte("[0123456789.*", s[i]) >= 0 {
+			switch s[i] {
+			case '*':
+				return nil, fmt.Errorf("%q: '*' width and precision are not supported", s)
+			case '[':
+				end := strings.IndexByte(s[i:], ']')
+				if end < 0 {
+					return nil, fmt.Errorf("%q: unterminated argument index", s)
+				}
+				var n int
+				if _, err := fmt.Sscanf(s[i+1:i+end], "%d", &n); err != nil || n < 1 {
+					return nil, fmt.Errorf("%q: bad argument index %q", s, s[i:i+end+1])
+				}
+				argNum = n - 1
+				i += end + 1
+			default:
+				i++
+			}
+		}
+		if i >= len(s) {
+			break
+		}
+		if !strings.ContainsRune("vTtbcdoOqxXUeEfFgGsp", rune(s[i])) {
+			continue
+		}
+
+		verbs = append(verbs, formatVerb{index: argNum, verb: s[i]})
+		argNum++
+	}
+	return verbs, nil
+}
+
+// formatArgs derives the typed constructor arguments of an item from the
+// verbs of its messages and the optional params declaration.
+// It fails when translations disagree on the argument count or types. The
+// "other" plural form stands for its language; the other forms may use a
+// subset of its arguments, e.g. one: "One failed attempt".
+func formatArgs(item ErrorItem) ([]formatArg, error) {
+	if item.isICU() {
+		return icuArgs(item)
+	}
+
+	langs := sortedLanguages(item.Message)
+
+	var types []string
+	var problems []string
+	count := -1
+	for _, lang := range langs {
+		langTypes, err := verbArgTypes(lang, item.Message[lang], &problems)
+		if err != nil {
+			return nil, err
+		}
+		for i, typ := range langTypes {
+			if typ == "" {
+				problems = append(problems, fmt.Sprintf("%s: argument %d is not used", lang, i+1))
+			}
+		}
+
+		if count == -1 {
+			count = len(langTypes)
+			types = langTypes
+			continue
+		}
+		if len(langTypes) != count {
+			problems = append(problems, fmt.Sprintf("%s has %d arguments, %s has %d", langs[0], count, lang, len(langTypes)))
+			continue
+		}
+		for i := range types {
+			typ, ok := mergeType(types[i], langTypes[i])
+			if !ok {
+				problems = append(problems, fmt.Sprintf("argument %d is %s in %s but %s in %s", i+1, types[i], langs[0], langTypes[i], lang))
+				continue
+			}
+			types[i] = typ
+		}
+	}
+
+	for _, lang := range langs {
+		forms := item.Plurals[lang]
+		for _, form := range sortedLanguages(forms) {
+			if form == gerr.PluralOther {
+				continue
+			}
+			name := lang + "/" + form
+			formTypes, err := verbArgTypes(name, forms[form], &problems)
+			if err != nil {
+				return nil, err
+			}
+			if len(formTypes) > len(types) {
+				problems = append(problems, fmt.Sprintf("%s uses %d arguments, %s/other has %d", name, len(formTypes), lang, len(types)))
+				continue
+			}
+			for i, typ := range formTypes {
+				if _, ok := mergeType(types[i], typ); !ok {
+					problems = append(problems, fmt.Sprintf("argument %d is %s in %s/other but %s in %s", i+1, types[i], lang, typ, name))
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("format arguments differ: %s", strings.Join(problems, "; "))
+	}
+
+	if len(item.Params) > 0 && len(item.Params) != len(types) {
+		return nil, fmt.Errorf("params declares %d arguments but messages use %d", len(item.Params), len(types))
+	}
+
+	args := make([]formatArg, len(types))
+	for i, typ := range types {
+		args[i] = formatArg{Name: fmt.Sprintf("arg%d", i+1), Type: typ}
+		if typ == "" {
+			args[i].Type = anyType
+		}
+		if i < len(item.Params) {
+			p := item.Params[i]
+			if !token.IsIdentifier(p.Name) {
+				return nil, fmt.Errorf("param %q is not a valid Go identifier", p.Name)
+			}
+			args[i].Name = p.Name
+			if p.Type != "" {
+				if _, err := parser.ParseExpr(p.Type); err != nil {
+					return nil, fmt.Errorf("param %q has invalid type %q", p.Name, p.Type)
+				}
+				args[i].Type = p.Type
+			}
+		}
+	}
+	return args, nil
+}
+
+// verbArgTypes returns the argument types used by the verbs of msg, with
+// the empty type for skipped arguments; type conflicts are added to problems
+func verbArgTypes(name, msg string, problems *[]string) ([]string, error) {
+	verbs, err := parseVerbs(msg)
+	if err != nil {
+		return nil, err
+	}
+	types := make([]string, 0)
+	for _, v := range verbs {
+		for len(types) <= v.index {
+			types = append(types, "")
+		}
+		typ, ok := mergeType(types[v.index], verbType(v.verb))
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s: argument %d used as %s and %s", name, v.index+1, types[v.index], verbType(v.verb)))
+			continue
+		}
+		types[v.index] = typ
+	}
+	return types, nil
+}
+
+func verbType(verb byte) string {
+	if typ, ok := verbTypes[verb]; ok {
+		return typ
+	}
+	return anyType
+}
+
+// mergeType combines two argument types; interface{} and the empty type
+// are compatible with anything
+func mergeType(a, b string) (string, bool) {
+	switch {
+	case a == "" || a == anyType:
+		if b == "" {
+			return a, true
+		}
+		return b, true
+	case b == "" || b == anyType || a == b:
+		return a, true
+	}
+	return "", false
+}
@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+// validateVisibility checks that no metadata key is both public and private
+func validateVisibility(item ErrorItem) error {
+	var both []string
+	for _, key := range item.PublicMetadata {
+		if contains(item.PrivateMetadata, key) {
+			both = append(both, key)
+		}
+	}
+	if len(both) > 0 {
+		return fmt.Errorf("metadata keys both public and private: %s", strings.Join(both, ", "))
+	}
+	return nil
+}
+
+// validateInternalMessage checks that the internal message only uses the
+// arguments of the public messages, with which it is rendered
+func validateInternalMessage(item ErrorItem) error {
+	if item.InternalMessage == "" {
+		return nil
+	}
+	args, err := formatArgs(item)
+	if err != nil {
+		// reported by the message checks
+		return nil
+	}
+
+	if item.isICU() {
+		used, err := gerr.ParseICUMessage(item.InternalMessage)
+		if err != nil {
+			return fmt.Errorf("internal_message: %v", err)
+		}
+		for _, u := range used {
+			found := false
+			for _, arg := range args {
+				found = found || arg.Param == u.Name
+			}
+			if !found {
+				return fmt.Errorf("internal_message uses {%s}, which the messages do not declare", u.Name)
+			}
+		}
+		return nil
+	}
+
+	verbs, err := parseVerbs(item.InternalMessage)
+	if err != nil {
+		return fmt.Errorf("internal_message: %v", err)
+	}
+	for _, v := range verbs {
+		if v.index >= len(args) {
+			return fmt.Errorf("internal_message uses argument %d, messages use %d", v.index+1, len(args))
+		}
+	}
+	for _, name := range placeholders(item.InternalMessage) {
+		if !contains(item.placeholderNames(), name) {
+			return fmt.Errorf("internal_message uses {%s}, which the messages do not declare", name)
+		}
+	}
+	return nil
+}
+
+// visibilityLiteral renders the metadata visibility as a Go map literal
+func visibilityLiteral(public, private []string) string {
+	keys := make(map[string]string, len(public)+len(private))
+	for _, k := range public {
+		keys[k] = "gerr.VisibilityPublic"
+	}
+	for _, k := range private {
+		keys[k] = "gerr.VisibilityPrivate"
+	}
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("map[string]gerr.Visibility{\n")
+	for _, k := range names {
+		fmt.Fprintf(&b, "\t\t%q: %s,\n", k, keys[k])
+	}
+	b.WriteString("\t}")
+	return b.String()
+}
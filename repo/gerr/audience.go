@@ -0,0 +1,103 @@
+package gerr
+
+import "context"
+
+// Audience selects who formatted output is meant for
+type Audience string
+
+const (
+	// AudienceInternal output carries everything: causes, stacks, all
+	// metadata and the internal message. It is the default.
+	AudienceInternal Audience = "internal"
+	// AudiencePublic output is safe to return to API clients: causes,
+	// stacks, the internal message and private metadata are redacted.
+	AudiencePublic Audience = "public"
+)
+
+// Visibility marks a metadata key as public or private
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityPrivate Visibility = "private"
+)
+
+// isPublic reports whether output for the audience must be redacted
+func (a Audience) isPublic() bool {
+	return a == AudiencePublic
+}
+
+// metadataVisibility returns the visibility of a metadata key: the one given
+// to With wins over the definition's; keys marked nowhere are private
+func (e *Error) metadataVisibility(key string) Visibility {
+	if v, ok := e.visibility[key]; ok {
+		return v
+	}
+	if e.errWrapper != nil {
+		if v, ok := e.errWrapper.MetadataVisibility[key]; ok {
+			return v
+		}
+	}
+	return VisibilityPrivate
+}
+
+// GetPublicMetadata returns the metadata entries marked public
+func (e *Error) GetPublicMetadata() map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range e.metadata {
+		if e.metadataVisibility(k) == VisibilityPublic {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// metadataFor returns the metadata visible to the audience
+func (e *Error) metadataFor(audience Audience) map[string]interface{} {
+	if audience.isPublic() {
+		return e.GetPublicMetadata()
+	}
+	return e.metadata
+}
+
+// GetInternalMessage returns the developer-facing message of the
+// definition, rendered with the error's args and params, or "" when the
+// definition has none
+func (e *Error) GetInternalMessage() string {
+	if e.errWrapper == nil || e.errWrapper.InternalMessage == "" {
+		return ""
+	}
+	return e.render(DefaultLanguage, e.errWrapper.InternalMessage)
+}
+
+// GetPublicMessage returns the message of the error as shown to public
+// audiences. Errors without a definition build their message from the
+// cause and args, which often carry internal details such as queries or
+// hostnames, so only their key is shown, or their code when the key is
+// empty. Errors with a definition return Error().
+func (e *Error) GetPublicMessage() string {
+	if e.errWrapper != nil {
+		return e.Error()
+	}
+	if e.key != "" {
+		return e.key
+	}
+	if e.code != "" {
+		return e.code
+	}
+	return "unknown error"
+}
+
+// localizeFor returns the message of err for the audience of options, in
+// options.Language or else the language of ctx
+func localizeFor(ctx context.Context, localizer Localizer, err *Error, options FormatOptions) string {
+	switch {
+	case options.Audience.isPublic() && err.errWrapper == nil:
+		return err.GetPublicMessage()
+	case localizer == nil:
+		return err.Error()
+	case options.Language != "":
+		return localizer.LocalizeWithLanguage(options.Language, err)
+	}
+	return localizer.Localize(ctx, err)
+}
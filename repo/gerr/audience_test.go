@@ -0,0 +1,65 @@
+package gerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const secret = "password authentication failed for user admin at 10.0.0.3"
+
+func TestPublicOutputHidesUndefinedCause(t *testing.T) {
+	err := Wrap(errors.New("pq: "+secret), "db_error")
+	ctx := context.Background()
+	formatter := NewDefaultFormatter()
+
+	for _, format := range []FormatType{FormatTypeStructured, FormatTypeJSON, FormatTypeText, FormatTypeProblem} {
+		t.Run(string(format), func(t *testing.T) {
+			out := formatter.FormatWithOptions(ctx, err, FormatOptions{
+				Format:          format,
+				Audience:        AudiencePublic,
+				IncludeCause:    true,
+				IncludeMetadata: true,
+			})
+			if strings.Contains(fmt.Sprint(out), secret) {
+				t.Errorf("cause leaked: %v", out)
+			}
+			if !strings.Contains(fmt.Sprint(out), "db_error") {
+				t.Errorf("output %v lacks the key", out)
+			}
+		})
+	}
+
+	internal := formatter.FormatWithOptions(ctx, err, FormatOptions{Format: FormatTypeStructured, Audience: AudienceInternal})
+	if msg := internal.(map[string]interface{})["message"]; !strings.Contains(msg.(string), secret) {
+		t.Errorf("internal message = %v, want the cause", msg)
+	}
+}
+
+func TestProblemDefaultsToPublic(t *testing.T) {
+	err := Wrap(errors.New(secret), "db_error")
+	problem := NewProblemFormatter("").FormatWithOptions(context.Background(), err, FormatOptions{})
+
+	if detail := problem.(map[string]interface{})["detail"]; detail != "db_error" {
+		t.Errorf("detail = %v, want db_error", detail)
+	}
+}
+
+func TestGetPublicMessage(t *testing.T) {
+	tests := []struct {
+		err  *Error
+		want string
+	}{
+		{Wrap(errors.New(secret), "db_error"), "db_error"},
+		{New("bad_input", secret), "bad_input"},
+		{Wrap(errors.New(secret), "").Code("DB_ERROR"), "DB_ERROR"},
+		{Wrap(errors.New(secret), ""), "unknown error"},
+	}
+	for _, tt := range tests {
+		if got := tt.err.GetPublicMessage(); got != tt.want {
+			t.Errorf("GetPublicMessage() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package gerr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bundleMessage is the translation of one error in a locale bundle
+type bundleMessage struct {
+	message string
+	plurals map[string]string
+}
+
+// localeBundles maps a language to the translations of its bundle, by error key
+type localeBundles map[string]map[string]bundleMessage
+
+// BundleLocalizer is a Localizer that overlays messages loaded at runtime
+// from per-language files on the messages of the registry.
+//
+// Each file is named after its language (es.yaml, pt-BR.json) and maps
+// error keys to a message, or to plural forms:
+//
+//	user_not_found: "Usuario no encontrado: %s"
+//	login_attempts_exceeded:
+//	  one: "%d intento fallido, cuenta bloqueada"
+//	  other: "%d intentos fallidos, cuenta bloqueada"
+//
+// Reload swaps in a new set of bundles atomically, so translations can be
+// updated without restarting.
+type BundleLocalizer struct {
+	fsys      fs.FS
+	localizer *DefaultLocalizer
+	bundles   atomic.Pointer[localeBundles]
+}
+
+// NewBundleLocalizer creates a localizer reading bundles from the root of
+// fsys. No bundle is loaded until Reload is called.
+func NewBundleLocalizer(fsys fs.FS) *BundleLocalizer {
+	l := &BundleLocalizer{
+		fsys:      fsys,
+		localizer: NewDefaultLocalizer(),
+	}
+	l.bundles.Store(&localeBundles{})
+	return l
+}
+
+// LoadBundles creates a localizer and loads the bundles of fsys
+func LoadBundles(fsys fs.FS) (*BundleLocalizer, error) {
+	l := NewBundleLocalizer(fsys)
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// LoadBundleDir creates a localizer and loads the bundles of a directory
+func LoadBundleDir(dir string) (*BundleLocalizer, error) {
+	return LoadBundles(os.DirFS(dir))
+}
+
+// SetRegistry sets the registry providing the compiled messages
+func (l *BundleLocalizer) SetRegistry(registry Registry) *BundleLocalizer {
+	l.localizer.SetRegistry(registry)
+	return l
+}
+
+// SetLanguagePolicy sets the fallback policy of the localizer
+func (l *BundleLocalizer) SetLanguagePolicy(policy LanguagePolicy) *BundleLocalizer {
+	l.localizer.SetLanguagePolicy(policy)
+	return l
+}
+
+// SetFallbackHook sets a function called whenever a message is served in
+// another language than requested
+func (l *BundleLocalizer) SetFallbackHook(hook func(FallbackEvent)) *BundleLocalizer {
+	l.localizer.SetFallbackHook(hook)
+	return l
+}
+
+// Reload reads every .yaml, .yml and .json file of the bundle directory
+// and replaces the loaded bundles at once. On error the bundles in use are
+// kept unchanged.
+func (l *BundleLocalizer) Reload() error {
+	entries, err := fs.ReadDir(l.fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	bundles := make(localeBundles)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		ext := path.Ext(name)
+		switch ext {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+
+		lang := strings.TrimSuffix(name, ext)
+		if _, ok := bundles[lang]; ok {
+			return fmt.Errorf("%s: duplicate bundle for language %q", name, lang)
+		}
+		messages, err := readBundle(l.fsys, name)
+		if err != nil {
+			return err
+		}
+		bundles[lang] = messages
+	}
+
+	l.bundles.Store(&bundles)
+	return nil
+}
+
+// readBundle parses one bundle file
+func readBundle(fsys fs.FS, name string) (map[string]bundleMessage, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make(map[string]interface{})
+	if path.Ext(name) == ".json" {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+
+	messages := make(map[string]bundleMessage, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			messages[key] = bundleMessage{message: v}
+		case map[string]interface{}:
+			forms := make(map[string]string, len(v))
+			for form, msg := range v {
+				s, ok := msg.(string)
+				if !IsPluralCategory(form) || !ok {
+					return nil, fmt.Errorf("%s: %s: invalid plural form %q", name, key, form)
+				}
+				forms[form] = s
+			}
+			messages[key] = bundleMessage{plurals: forms}
+		default:
+			return nil, fmt.Errorf("%s: %s: message must be a string or plural forms", name, key)
+		}
+	}
+	return messages, nil
+}
+
+// overlay returns a copy of err whose definition carries the bundle
+// messages of its key; err is returned as is when no bundle has any
+func (l *BundleLocalizer) overlay(err *Error) *Error {
+	if err.errWrapper == nil {
+		return err
+	}
+
+	var def *ErrWrapper
+	for lang, messages := range *l.bundles.Load() {
+		msg, ok := messages[err.key]
+		if !ok {
+			continue
+		}
+		if def == nil {
+			copied := err.errWrapper.clone()
+			def = &copied
+			if def.Messages == nil {
+				def.Messages = make(map[string]string)
+			}
+		}
+		if msg.plurals != nil {
+			if def.Plurals == nil {
+				def.Plurals = make(map[string]map[string]string)
+			}
+			def.Plurals[lang] = msg.plurals
+			continue
+		}
+		def.Messages[lang] = msg.message
+		delete(def.Plurals, lang)
+	}
+	if def == nil {
+		return err
+	}
+
+	result := err.clone()
+	result.errWrapper = def
+	return result
+}
+
+// Localize returns a localized message for the language of ctx
+func (l *BundleLocalizer) Localize(ctx context.Context, err *Error) string {
+	lang := l.localizer.languagePolicy().defaultLanguage(l.localizer.registry)
+	if v, ok := LanguageFrom(ctx); ok {
+		lang = v
+	}
+	return l.LocalizeWithLanguage(lang, err)
+}
+
+// LocalizeWithLanguage returns a localized message for a specific language,
+// preferring bundle messages over compiled ones
+func (l *BundleLocalizer) LocalizeWithLanguage(language string, err *Error) string {
+	msg, localizeErr := l.LocalizeStrict(language, err)
+	if errors.Is(localizeErr, ErrMissingTranslation) {
+		return err.missingMessage(language)
+	}
+	return msg
+}
+
+// LocalizeStrict is like LocalizeWithLanguage but reports a missing
+// message in strict mode as ErrMissingTranslation
+func (l *BundleLocalizer) LocalizeStrict(language string, err *Error) (string, error) {
+	return l.localizer.LocalizeStrict(language, l.overlay(err))
+}
+
+// NegotiateLanguage picks the supported language that best matches an
+// Accept-Language header, falling back to the default language
+func (l *BundleLocalizer) NegotiateLanguage(acceptLanguage string) string {
+	if lang := MatchLanguage(acceptLanguage, l.GetSupportedLanguages()); lang != "" {
+		return lang
+	}
+	return l.localizer.languagePolicy().defaultLanguage(l.localizer.registry)
+}
+
+// GetSupportedLanguages returns the languages of the registry and of the
+// loaded bundles
+func (l *BundleLocalizer) GetSupportedLanguages() []string {
+	languages := make(map[string]bool)
+	for _, lang := range l.localizer.GetSupportedLanguages() {
+		languages[lang] = true
+	}
+	for lang := range *l.bundles.Load() {
+		languages[lang] = true
+	}
+
+	result := make([]string, 0, len(languages))
+	for lang := range languages {
+		result = append(result, lang)
+	}
+	sortLanguages(result)
+	return result
+}
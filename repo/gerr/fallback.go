@@ -0,0 +1,126 @@
+package gerr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrMissingTranslation is returned in strict mode when no message exists
+// for the requested language or its fallbacks
+var ErrMissingTranslation = errors.New("missing translation")
+
+// LanguagePolicy controls which message is used when the requested
+// language has none
+type LanguagePolicy struct {
+	// Fallbacks maps a language to the languages tried after it,
+	// e.g. "pt-BR": {"pt", "es"}. A regional language such as "pt-BR"
+	// always falls back to its base language "pt" first.
+	Fallbacks map[string][]string `json:"fallbacks,omitempty" yaml:"fallbacks,omitempty"`
+
+	// DefaultLanguage is tried after the fallback chain. When empty the
+	// registry default language is used, then DefaultLanguage ("en").
+	DefaultLanguage string `json:"default_language,omitempty" yaml:"default_language,omitempty"`
+
+	// Strict disables the last resort of using any available translation;
+	// a missing message then yields a marker or ErrMissingTranslation.
+	Strict bool `json:"strict,omitempty" yaml:"strict,omitempty"`
+}
+
+// defaultLanguager is implemented by registries with a default language
+type defaultLanguager interface {
+	DefaultLanguage() string
+}
+
+var globalPolicy atomic.Pointer[LanguagePolicy]
+
+func init() {
+	globalPolicy.Store(&LanguagePolicy{})
+}
+
+// SetLanguagePolicy sets the policy used by Error, GetMessage and localizers
+// that have no policy of their own
+func SetLanguagePolicy(policy LanguagePolicy) {
+	globalPolicy.Store(&policy)
+}
+
+// GetLanguagePolicy returns the global language policy
+func GetLanguagePolicy() LanguagePolicy {
+	return *globalPolicy.Load()
+}
+
+// defaultLanguage returns the language tried after the fallback chain
+func (p LanguagePolicy) defaultLanguage(registry Registry) string {
+	if p.DefaultLanguage != "" {
+		return p.DefaultLanguage
+	}
+	if r, ok := registry.(defaultLanguager); ok && r.DefaultLanguage() != "" {
+		return r.DefaultLanguage()
+	}
+	return DefaultLanguage
+}
+
+// chain returns the languages to try for lang, in order and without
+// duplicates: lang, its fallbacks, its base language and the base's fallbacks
+func (p LanguagePolicy) chain(lang string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	add := func(langs ...string) {
+		for _, l := range langs {
+			if l != "" && !seen[l] {
+				seen[l] = true
+				result = append(result, l)
+			}
+		}
+	}
+
+	add(lang)
+	add(p.Fallbacks[lang]...)
+	if base, _, ok := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-"); ok {
+		add(base)
+		add(p.Fallbacks[base]...)
+	}
+	return result
+}
+
+// resolveTemplate picks the message template for lang following policy.
+// It returns the template and the language it was found in; ok is false
+// when strict mode leaves no message.
+func (e *Error) resolveTemplate(lang string, policy LanguagePolicy, registry Registry) (msg string, used string, ok bool) {
+	if e.errWrapper == nil {
+		return "", "", false
+	}
+
+	for _, l := range policy.chain(lang) {
+		if msg, ok := e.messageTemplate(l); ok {
+			return msg, l, true
+		}
+	}
+
+	// Try the closest language, e.g. "cn" for "zh-Hans-CN"
+	languages := e.messageLanguages()
+	if l := MatchLanguage(lang, languages); l != "" {
+		msg, _ := e.messageTemplate(l)
+		return msg, l, true
+	}
+
+	defaultLang := policy.defaultLanguage(registry)
+	for _, l := range policy.chain(defaultLang) {
+		if msg, ok := e.messageTemplate(l); ok {
+			return msg, l, true
+		}
+	}
+
+	if policy.Strict || len(languages) == 0 {
+		return "", "", false
+	}
+	// Last resort: the first language in a stable order
+	msg, _ = e.messageTemplate(languages[0])
+	return msg, languages[0], true
+}
+
+// missingMessage is the marker rendered in strict mode when no message exists
+func (e *Error) missingMessage(lang string) string {
+	return fmt.Sprintf("[%s] missing %s message", e.code, lang)
+}
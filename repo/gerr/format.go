@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // FormatOptions controls error formatting
 type FormatOptions struct {
 	IncludeMetadata bool       `json:"include_metadata"`
 	IncludeCause    bool       `json:"include_cause"`
+	IncludeStack    bool       `json:"include_stack"`
 	Language        string     `json:"language,omitempty"`
-	Format          FormatType `json:"format,omitempty"` // "json", "text", "structured"
+	Format          FormatType `json:"format,omitempty"`   // "json", "text", "structured", "problem"
+	Instance        string     `json:"instance,omitempty"` // problem "instance" URI
+	Audience        Audience   `json:"audience,omitempty"` // AudiencePublic redacts causes, stacks and private metadata
 }
 
 type FormatType string
@@ -20,11 +24,31 @@ const (
 	FormatTypeJSON       FormatType = "json"
 	FormatTypeText       FormatType = "text"
 	FormatTypeStructured FormatType = "structured"
+	FormatTypeProblem    FormatType = "problem" // RFC 9457 problem details
 )
 
+// formatOptionsKey is the context key for format options
+type formatOptionsKey struct{}
+
+// WithFormatOptions returns a copy of ctx carrying format options, letting
+// callers of Process choose the output of the default formatter
+func WithFormatOptions(ctx context.Context, options FormatOptions) context.Context {
+	return context.WithValue(ctx, formatOptionsKey{}, options)
+}
+
+// FormatOptionsFrom returns the format options stored in ctx by WithFormatOptions
+func FormatOptionsFrom(ctx context.Context) (FormatOptions, bool) {
+	if ctx == nil {
+		return FormatOptions{}, false
+	}
+	options, ok := ctx.Value(formatOptionsKey{}).(FormatOptions)
+	return options, ok
+}
+
 // DefaultFormatter is the default implementation of Formatter
 type DefaultFormatter struct {
-	localizer Localizer
+	localizer       Localizer
+	problemTypeBase string
 }
 
 // NewDefaultFormatter creates a new default formatter
@@ -34,12 +58,27 @@ func NewDefaultFormatter() *DefaultFormatter {
 	}
 }
 
-// Format formats an error for output
+// SetLocalizer sets the localizer used for messages
+func (f *DefaultFormatter) SetLocalizer(localizer Localizer) *DefaultFormatter {
+	f.localizer = localizer
+	return f
+}
+
+// SetProblemTypeBase sets the base URI of the problem "type" member
+func (f *DefaultFormatter) SetProblemTypeBase(typeBase string) *DefaultFormatter {
+	f.problemTypeBase = typeBase
+	return f
+}
+
+// Format formats an error for output in the language of ctx, with the
+// options stored in ctx by WithFormatOptions when present
 func (f *DefaultFormatter) Format(ctx context.Context, err *Error) interface{} {
+	if options, ok := FormatOptionsFrom(ctx); ok {
+		return f.FormatWithOptions(ctx, err, options)
+	}
 	return f.FormatWithOptions(ctx, err, FormatOptions{
 		IncludeMetadata: true,
 		IncludeCause:    true,
-		Language:        "en",
 		Format:          FormatTypeStructured,
 	})
 }
@@ -51,6 +90,9 @@ func (f *DefaultFormatter) FormatWithOptions(ctx context.Context, err *Error, op
 		return f.formatJSON(ctx, err, options)
 	case FormatTypeText:
 		return f.formatText(ctx, err, options)
+	case FormatTypeProblem:
+		problem := &ProblemFormatter{localizer: f.localizer, typeBase: f.problemTypeBase}
+		return problem.formatProblem(ctx, err, options)
 	default:
 		return f.formatStructured(ctx, err, options)
 	}
@@ -61,15 +103,34 @@ func (f *DefaultFormatter) formatStructured(ctx context.Context, err *Error, opt
 	result := map[string]interface{}{
 		"key":     err.key,
 		"code":    err.code,
-		"message": f.localizer.LocalizeWithLanguage(options.Language, err),
+		"message": localizeFor(ctx, f.localizer, err, options),
 		"time":    err.time,
 	}
 
-	if options.IncludeMetadata && len(err.metadata) > 0 {
-		result["metadata"] = err.metadata
+	if metadata := err.metadataFor(options.Audience); options.IncludeMetadata && len(metadata) > 0 {
+		result["metadata"] = metadata
+	}
+
+	if !options.Audience.isPublic() {
+		if msg := err.GetInternalMessage(); msg != "" {
+			result["internal_message"] = msg
+		}
+		if options.IncludeStack && len(err.stack) > 0 {
+			result["stack"] = formatFrames(err.StackTrace())
+		}
 	}
 
-	if options.IncludeCause && err.cause != nil {
+	if list, ok := err.cause.(*ErrorList); ok {
+		errs := make([]map[string]interface{}, 0, list.Len())
+		for _, fe := range list.errs {
+			item := f.formatStructured(ctx, fe.Err, options)
+			if fe.Field != "" {
+				item["field"] = fe.Field
+			}
+			errs = append(errs, item)
+		}
+		result["errors"] = errs
+	} else if options.IncludeCause && !options.Audience.isPublic() && err.cause != nil {
 		if causeErr, ok := err.cause.(*Error); ok {
 			result["cause"] = f.formatStructured(ctx, causeErr, options)
 		} else {
@@ -80,6 +141,12 @@ func (f *DefaultFormatter) formatStructured(ctx context.Context, err *Error, opt
 	if err.errWrapper != nil {
 		result["category"] = err.errWrapper.Category
 		result["severity"] = string(err.errWrapper.Severity)
+		if err.errWrapper.Retryable {
+			result["retryable"] = true
+			if err.errWrapper.RetryAfter > 0 {
+				result["retry_after"] = retryAfterSeconds(err.errWrapper.RetryAfter)
+			}
+		}
 	}
 
 	return result
@@ -97,28 +164,49 @@ func (f *DefaultFormatter) formatText(ctx context.Context, err *Error, options F
 	var parts []string
 
 	// Basic error info
-	if f.localizer != nil {
-		if options.Language != "" {
-			parts = append(parts, f.localizer.LocalizeWithLanguage(options.Language, err))
-		} else {
-			parts = append(parts, f.localizer.Localize(ctx, err))
-		}
-	} else {
-		parts = append(parts, err.Error())
-	}
+	parts = append(parts, localizeFor(ctx, f.localizer, err, options))
 
 	// Add code if available
 	if err.code != "" {
 		parts = append(parts, fmt.Sprintf("Code: %s", err.code))
 	}
 
+	// Add retry hint
+	if err.errWrapper != nil && err.errWrapper.Retryable {
+		if err.errWrapper.RetryAfter > 0 {
+			parts = append(parts, fmt.Sprintf("Retry-After: %s", err.errWrapper.RetryAfter))
+		} else {
+			parts = append(parts, "Retryable: true")
+		}
+	}
+
+	// Add the internal message
+	if msg := err.GetInternalMessage(); msg != "" && !options.Audience.isPublic() {
+		parts = append(parts, fmt.Sprintf("Internal: %s", msg))
+	}
+
 	// Add metadata
-	if options.IncludeMetadata && len(err.metadata) > 0 {
-		parts = append(parts, fmt.Sprintf("Metadata: %v", err.metadata))
+	if metadata := err.metadataFor(options.Audience); options.IncludeMetadata && len(metadata) > 0 {
+		parts = append(parts, fmt.Sprintf("Metadata: %v", metadata))
 	}
 
-	// Add cause
-	if options.IncludeCause && err.cause != nil {
+	// Add stack
+	if options.IncludeStack && !options.Audience.isPublic() && len(err.stack) > 0 {
+		parts = append(parts, "Stack:\n\t"+strings.Join(formatFrames(err.StackTrace()), "\n\t"))
+	}
+
+	// Add list members, or the cause
+	if list, ok := err.cause.(*ErrorList); ok {
+		lines := make([]string, 0, list.Len())
+		for _, fe := range list.errs {
+			line := f.formatText(ctx, fe.Err, FormatOptions{Language: options.Language, Audience: options.Audience})
+			if fe.Field != "" {
+				line = fe.Field + ": " + line
+			}
+			lines = append(lines, "- "+strings.ReplaceAll(line, "\n", "\n  "))
+		}
+		parts = append(parts, "Errors:\n"+strings.Join(lines, "\n"))
+	} else if options.IncludeCause && !options.Audience.isPublic() && err.cause != nil {
 		if causeErr, ok := err.cause.(*Error); ok {
 			parts = append(parts, fmt.Sprintf("Cause: %s", f.formatText(ctx, causeErr, options)))
 		} else {
@@ -10,21 +10,34 @@ type Error struct {
 	code       string
 	key        string
 	args       []interface{}
+	params     map[string]interface{}
 	metadata   map[string]interface{}
+	visibility map[string]Visibility // metadata visibility set by With
 	cause      error
 	time       time.Time
+	stack      []uintptr
 	errWrapper *ErrWrapper
+	message    string // message of a decoded error whose definition is unknown
+	template   bool   // created by NewError and not derived from yet
 }
 
-// NewError creates a new error
+// NewError creates a new error from a definition.
+// The definition is copied, so later changes to err do not leak into the
+// returned value. Every method on *Error returns a fresh instance, which makes
+// the result safe to keep in a package-level variable and share between
+// goroutines. The result is a template: the first Args, With, Params, Code
+// or Wrap call on it starts an instance with its own time and stack.
 func NewError(err ErrWrapper) *Error {
+	def := err.clone()
 	return &Error{
-		key:        err.Key,
-		code:       err.Code,
+		key:        def.Key,
+		code:       def.Code,
 		args:       nil,
 		metadata:   make(map[string]interface{}),
 		time:       time.Now(),
-		errWrapper: &err,
+		stack:      callers(0),
+		errWrapper: &def,
+		template:   true,
 	}
 }
 
@@ -35,6 +48,7 @@ func New(key string, args ...interface{}) *Error {
 		args:     args,
 		metadata: make(map[string]interface{}),
 		time:     time.Now(),
+		stack:    callers(0),
 	}
 }
 
@@ -46,34 +60,82 @@ func Wrap(cause error, key string, args ...interface{}) *Error {
 		cause:    cause,
 		metadata: make(map[string]interface{}),
 		time:     time.Now(),
+		stack:    callers(0),
 	}
 }
 
-// Code sets the error code
+// Code returns a copy of the error with the given code
 func (e *Error) Code(code string) *Error {
-	e.code = code
-	return e
+	newE := e.derive()
+	newE.code = code
+	return newE
+}
+
+// clone returns a copy of the error that owns its args and metadata,
+// so the copy can be changed without affecting the original.
+func (e *Error) clone() *Error {
+	newE := *e
+	if e.args != nil {
+		newE.args = append([]interface{}(nil), e.args...)
+	}
+	if e.params != nil {
+		newE.params = make(map[string]interface{}, len(e.params))
+		for k, v := range e.params {
+			newE.params[k] = v
+		}
+	}
+	newE.metadata = make(map[string]interface{}, len(e.metadata))
+	for k, v := range e.metadata {
+		newE.metadata[k] = v
+	}
+	if e.visibility != nil {
+		newE.visibility = make(map[string]Visibility, len(e.visibility))
+		for k, v := range e.visibility {
+			newE.visibility[k] = v
+		}
+	}
+	return &newE
+}
+
+// Message returns a copy of the error whose Error method returns msg
+// instead of rendering the definition, for errors rebuilt without their
+// args such as those received in a gRPC status. Args and Params clear it.
+func (e *Error) Message(msg string) *Error {
+	newE := e.derive()
+	newE.message = msg
+	return newE
+}
+
+// derive returns a copy of the error for a chained method. Deriving from a
+// template starts an instance whose time and stack are those of the call.
+func (e *Error) derive() *Error {
+	newE := e.clone()
+	if e.template {
+		newE.template = false
+		newE.time = time.Now()
+		newE.stack = callers(1)
+	}
+	return newE
 }
 
 // Error implements the error interface
 func (e *Error) Error() string {
+	if e.message != "" {
+		return e.message
+	}
 	if e.errWrapper != nil {
-		msg := ""
-		if enMsg, ok := e.errWrapper.Messages["en"]; ok {
-			msg = enMsg
-		} else {
-			// Use first available message
-			for _, m := range e.errWrapper.Messages {
-				msg = m
-				break
-			}
+		policy := GetLanguagePolicy()
+		lang := policy.defaultLanguage(globalRegistry)
+		msg, used, ok := e.resolveTemplate(lang, policy, globalRegistry)
+		if !ok {
+			return e.missingMessage(lang)
 		}
 
-		// Format with args if available
-		if len(e.args) > 0 {
-			return fmt.Sprintf(msg, e.args...)
+		// Format with args and params if available
+		if e.hasValues() {
+			return e.render(used, msg)
 		}
-		return fmt.Sprintf("[%s] %s", e.code, msg)
+		return fmt.Sprintf("[%s] %s", e.code, e.render(used, msg))
 	}
 
 	if e.cause != nil {
@@ -84,30 +146,41 @@ func (e *Error) Error() string {
 
 // Args creates a new Error instance with arguments
 func (e *Error) Args(args ...interface{}) *Error {
+	newE := e.clone()
 	if e.errWrapper == nil {
-		newE := *e
 		newE.args = append(newE.args, args...)
-		return &newE
+		return newE
 	}
 
-	// create a new instance
-	return &Error{
-		key:        e.key,
-		code:       e.code,
-		args:       args,
-		metadata:   make(map[string]interface{}),
-		time:       time.Now(),
-		errWrapper: e.errWrapper,
-	}
+	// a defined error starts a new instance with its own args
+	newE.args = append([]interface{}(nil), args...)
+	newE.message = ""
+	newE.template = false
+	newE.time = time.Now()
+	newE.stack = callers(0)
+	return newE
 }
 
-// With adds metadata to the error
-func (e *Error) With(key string, value interface{}) *Error {
-	if e.metadata == nil {
-		e.metadata = make(map[string]interface{})
+// With returns a copy of the error with the metadata added.
+// An optional visibility marks the key public or private for this error,
+// overriding the definition; see FormatOptions.Audience.
+func (e *Error) With(key string, value interface{}, visibility ...Visibility) *Error {
+	newE := e.derive()
+	newE.metadata[key] = value
+	if len(visibility) > 0 {
+		if newE.visibility == nil {
+			newE.visibility = make(map[string]Visibility)
+		}
+		newE.visibility[key] = visibility[0]
 	}
-	e.metadata[key] = value
-	return e
+	return newE
+}
+
+// Wrap returns a copy of the error that wraps cause
+func (e *Error) Wrap(cause error) *Error {
+	newE := e.derive()
+	newE.cause = cause
+	return newE
 }
 
 // Unwrap returns the wrapped error for error unwrapping
@@ -125,9 +198,12 @@ func (e *Error) GetCode() string {
 	return e.code
 }
 
-// GetArgs returns the error arguments
+// GetArgs returns a copy of the error arguments
 func (e *Error) GetArgs() []interface{} {
-	return e.args
+	if e.args == nil {
+		return nil
+	}
+	return append([]interface{}(nil), e.args...)
 }
 
 // GetMetadata returns the error metadata
@@ -149,15 +225,19 @@ func (e *Error) GetTime() time.Time {
 	return e.time
 }
 
-// GetErrWrapper returns the error definition
+// GetErrWrapper returns a copy of the error definition
 func (e *Error) GetErrWrapper() *ErrWrapper {
-	return e.errWrapper
+	if e.errWrapper == nil {
+		return nil
+	}
+	def := e.errWrapper.clone()
+	return &def
 }
 
 // GetMessages returns all messages
 func (e *Error) GetMessages() map[string]string {
 	if e.errWrapper != nil {
-		return e.errWrapper.Messages
+		return e.errWrapper.clone().Messages
 	}
 	return nil
 }
@@ -168,18 +248,24 @@ func (e *Error) GetMessage(lang string) string {
 		return ""
 	}
 
-	if msg, ok := e.errWrapper.Messages[lang]; ok {
+	// Follow the global language policy
+	if msg, _, ok := e.resolveTemplate(lang, GetLanguagePolicy(), globalRegistry); ok {
 		return msg
 	}
-	// Fallback to English
-	if msg, ok := e.errWrapper.Messages["en"]; ok {
-		return msg
+	return e.key
+}
+
+// messageLanguages returns the languages of the definition's messages
+func (e *Error) messageLanguages() []string {
+	if e.errWrapper == nil {
+		return nil
 	}
-	// Fallback to first available
-	for _, msg := range e.errWrapper.Messages {
-		return msg
+	languages := make([]string, 0, len(e.errWrapper.Messages))
+	for lang := range e.errWrapper.Messages {
+		languages = append(languages, lang)
 	}
-	return e.key
+	sortLanguages(languages)
+	return languages
 }
 
 // Is implements error comparison for errors.Is
@@ -197,13 +283,59 @@ func (e *Error) Is(target error) bool {
 
 // ErrWrapper represents an error wrapper
 type ErrWrapper struct {
-	Key         string                 `json:"key" yaml:"key"`
-	Code        string                 `json:"code" yaml:"code"`
-	Messages    map[string]string      `json:"messages" yaml:"messages"`
-	Description string                 `json:"description,omitempty" yaml:"description,omitempty"`
-	Category    string                 `json:"category,omitempty" yaml:"category,omitempty"`
-	Severity    Severity               `json:"severity,omitempty" yaml:"severity,omitempty"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Key                string                       `json:"key" yaml:"key"`
+	Code               string                       `json:"code" yaml:"code"`
+	Messages           map[string]string            `json:"messages" yaml:"messages"`
+	InternalMessage    string                       `json:"internal_message,omitempty" yaml:"internal_message,omitempty"`
+	Description        string                       `json:"description,omitempty" yaml:"description,omitempty"`
+	Category           string                       `json:"category,omitempty" yaml:"category,omitempty"`
+	Severity           Severity                     `json:"severity,omitempty" yaml:"severity,omitempty"`
+	HTTPStatus         int                          `json:"http_status,omitempty" yaml:"http_status,omitempty"`
+	GRPCCode           string                       `json:"grpc_code,omitempty" yaml:"grpc_code,omitempty"`
+	Retryable          bool                         `json:"retryable,omitempty" yaml:"retryable,omitempty"`
+	Plurals            map[string]map[string]string `json:"plurals,omitempty" yaml:"plurals,omitempty"`
+	PluralArg          string                       `json:"plural_arg,omitempty" yaml:"plural_arg,omitempty"`
+	MessageFormat      string                       `json:"message_format,omitempty" yaml:"message_format,omitempty"`
+	RetryAfter         time.Duration                `json:"retry_after,omitempty" yaml:"retry_after,omitempty"`
+	Metadata           map[string]interface{}       `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	MetadataVisibility map[string]Visibility        `json:"metadata_visibility,omitempty" yaml:"metadata_visibility,omitempty"`
+}
+
+// clone returns a copy of the definition that does not share its maps
+func (w ErrWrapper) clone() ErrWrapper {
+	if w.Messages != nil {
+		messages := make(map[string]string, len(w.Messages))
+		for k, v := range w.Messages {
+			messages[k] = v
+		}
+		w.Messages = messages
+	}
+	if w.Plurals != nil {
+		plurals := make(map[string]map[string]string, len(w.Plurals))
+		for lang, forms := range w.Plurals {
+			copied := make(map[string]string, len(forms))
+			for k, v := range forms {
+				copied[k] = v
+			}
+			plurals[lang] = copied
+		}
+		w.Plurals = plurals
+	}
+	if w.Metadata != nil {
+		metadata := make(map[string]interface{}, len(w.Metadata))
+		for k, v := range w.Metadata {
+			metadata[k] = v
+		}
+		w.Metadata = metadata
+	}
+	if w.MetadataVisibility != nil {
+		visibility := make(map[string]Visibility, len(w.MetadataVisibility))
+		for k, v := range w.MetadataVisibility {
+			visibility[k] = v
+		}
+		w.MetadataVisibility = visibility
+	}
+	return w
 }
 
 // Severity represents error severity levels
@@ -0,0 +1,152 @@
+package gerr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var templateDef = ErrWrapper{
+	Key:      "template_test",
+	Code:     "TEMPLATE_TEST",
+	Category: "resource",
+	Severity: SeverityError,
+	Messages: map[string]string{
+		"en": "Item {name} not found: %s",
+	},
+	Metadata: map[string]interface{}{"source": "test"},
+}
+
+func TestTemplateConcurrentUse(t *testing.T) {
+	template := NewError(templateDef).With("base", "value")
+	wantMetadata := template.GetMetadata()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprint(i)
+			err := template.
+				Args(id).
+				With("request", id).
+				Params(map[string]interface{}{"name": id}).
+				Code("CODE_" + id)
+
+			if got := err.GetMetadata()["request"]; got != id {
+				t.Errorf("metadata request = %v, want %s", got, id)
+			}
+			if got := err.GetCode(); got != "CODE_"+id {
+				t.Errorf("code = %s, want CODE_%s", got, id)
+			}
+			if got := err.GetArgs(); len(got) != 1 || got[0] != id {
+				t.Errorf("args = %v, want [%s]", got, id)
+			}
+			_ = err.Error()
+		}(i)
+	}
+	wg.Wait()
+
+	if got := template.GetMetadata(); !reflect.DeepEqual(got, wantMetadata) {
+		t.Errorf("template metadata changed: %v, want %v", got, wantMetadata)
+	}
+	if got := template.GetArgs(); len(got) != 0 {
+		t.Errorf("template args changed: %v", got)
+	}
+	if got := template.GetParams(); len(got) != 0 {
+		t.Errorf("template params changed: %v", got)
+	}
+	if got := template.GetCode(); got != "TEMPLATE_TEST" {
+		t.Errorf("template code changed: %s", got)
+	}
+}
+
+func TestGetArgsReturnsCopy(t *testing.T) {
+	err := NewError(templateDef).Args("a")
+	args := err.GetArgs()
+	args[0] = "changed"
+
+	if got := err.GetArgs()[0]; got != "a" {
+		t.Errorf("args = %v after changing the returned slice, want a", got)
+	}
+}
+
+func TestFormatVerbs(t *testing.T) {
+	err := NewError(templateDef).Args("42")
+	msg := err.Error()
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%s", msg},
+		{"%v", msg},
+		{"%q", fmt.Sprintf("%q", msg)},
+		{"%d", "%!d(*gerr.Error=" + msg + ")"},
+		{"%x", "%!x(*gerr.Error=" + msg + ")"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, err); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+// packageTemplate is created at init, before the test sets a stack mode,
+// like the templates of generated code
+var packageTemplate = NewError(templateDef)
+
+func TestTemplateMethodsStartInstances(t *testing.T) {
+	SetStackMode(StackModeFull)
+	defer SetStackMode(StackModeOff)
+	time.Sleep(time.Millisecond)
+
+	tests := map[string]func() *Error{
+		"Args": func() *Error { return packageTemplate.Args("42") },
+		"With": func() *Error { return packageTemplate.With("id", 42) },
+		"Code": func() *Error { return packageTemplate.Code("OTHER") },
+		"Wrap": func() *Error { return packageTemplate.Wrap(errors.New("cause")) },
+		// typed ICU constructors only set params
+		"Params": func() *Error { return packageTemplate.Params(map[string]interface{}{"name": "x"}) },
+	}
+	for name, create := range tests {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			err := create()
+
+			if err.GetTime().Before(start) {
+				t.Errorf("time = %v, want the time of the call (after %v)", err.GetTime(), start)
+			}
+			frames := err.StackTrace()
+			if len(frames) == 0 || !strings.Contains(frames[0].Function, "TestTemplateMethodsStartInstances") {
+				t.Errorf("stack starts at %v, want the caller", frames)
+			}
+
+			// further calls on the instance keep its time and stack
+			next := err.With("more", 1)
+			if !next.GetTime().Equal(err.GetTime()) || len(next.StackTrace()) != len(frames) {
+				t.Error("chained call on an instance restarted it")
+			}
+		})
+	}
+
+	if len(packageTemplate.StackTrace()) != 0 {
+		t.Error("template was changed")
+	}
+}
+
+func TestRenderParamWithPercent(t *testing.T) {
+	err := NewError(templateDef).Args("x").Params(map[string]interface{}{"name": "50%d off"})
+	if got, want := err.Error(), "Item 50%d off not found: x"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	onlyParams := NewError(templateDef).Params(map[string]interface{}{"name": "100%"})
+	if got, want := onlyParams.Error(), "Item 100% not found: %s"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+// Package ginx integrates gerr with the Gin web framework.
+package ginx
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+// Middleware turns the errors of a request into formatted responses
+type Middleware struct {
+	engine      *gerr.ProcessorEngine
+	contentType string
+	negotiate   func(acceptLanguage string) string
+	fallback    gerr.ErrWrapper
+	recover     bool
+	hook        func(c *gin.Context, err *gerr.Error)
+}
+
+// NewMiddleware creates a middleware that recovers panics and writes RFC 9457
+// problem details for public consumption
+func NewMiddleware() *Middleware {
+	return &Middleware{
+		engine:      gerr.NewProcessorEngine().SetFormatter(gerr.NewProblemFormatter("")),
+		contentType: gerr.ProblemContentType,
+		negotiate:   gerr.NegotiateLanguage,
+		fallback:    gerr.InternalError,
+		recover:     true,
+	}
+}
+
+// SetEngine sets the engine that formats errors. Set the content type to
+// match its formatter with SetContentType.
+func (m *Middleware) SetEngine(engine *gerr.ProcessorEngine) *Middleware {
+	m.engine = engine
+	return m
+}
+
+// SetContentType sets the Content-Type of formatted responses
+func (m *Middleware) SetContentType(contentType string) *Middleware {
+	m.contentType = contentType
+	return m
+}
+
+// SetLanguageNegotiator sets the function picking the response language
+// from the Accept-Language header, e.g. a localizer's NegotiateLanguage
+func (m *Middleware) SetLanguageNegotiator(negotiate func(acceptLanguage string) string) *Middleware {
+	m.negotiate = negotiate
+	return m
+}
+
+// SetFallback sets the definition wrapping errors that are not *gerr.Error
+// and recovered panics, gerr.InternalError by default
+func (m *Middleware) SetFallback(def gerr.ErrWrapper) *Middleware {
+	m.fallback = def
+	return m
+}
+
+// SetRecover enables or disables panic recovery
+func (m *Middleware) SetRecover(enabled bool) *Middleware {
+	m.recover = enabled
+	return m
+}
+
+// SetErrorHook sets a function called with every error before it is
+// written, e.g. to log it
+func (m *Middleware) SetErrorHook(hook func(c *gin.Context, err *gerr.Error)) *Middleware {
+	m.hook = hook
+	return m
+}
+
+// Handler returns the Gin handler. It writes the last error added with
+// c.Error or a recovered panic. When the handler already started the
+// response, e.g. with c.AbortWithError, the error is only passed to the
+// error hook.
+func (m *Middleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.recover {
+			defer func() {
+				if r := recover(); r != nil {
+					if r == http.ErrAbortHandler {
+						panic(r)
+					}
+					m.respond(c, gerr.Recovered(r, m.fallback))
+				}
+			}()
+		}
+
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		m.respond(c, gerr.AsError(c.Errors.Last().Err, m.fallback))
+	}
+}
+
+// respond writes err unless the response was already started, in which
+// case it is only reported
+func (m *Middleware) respond(c *gin.Context, err *gerr.Error) {
+	if c.Writer.Written() {
+		m.report(c, err)
+		return
+	}
+	m.write(c, err)
+}
+
+// report passes err to the error hook, if any
+func (m *Middleware) report(c *gin.Context, err *gerr.Error) {
+	if m.hook != nil {
+		m.hook(c, err)
+	}
+}
+
+// write formats err in the negotiated language and writes it with its
+// HTTP status
+func (m *Middleware) write(c *gin.Context, err *gerr.Error) {
+	m.report(c, err)
+
+	lang := m.negotiate(c.GetHeader("Accept-Language"))
+	ctx := gerr.WithLanguage(c.Request.Context(), lang)
+	result := m.engine.Process(ctx, err)
+
+	status := err.HTTPStatus()
+	gerr.SetRetryAfterHeader(c.Writer.Header(), err)
+	c.Header("Content-Language", lang)
+	c.Abort()
+
+	if text, ok := result.(string); ok {
+		c.String(status, text)
+		return
+	}
+	if m.contentType != "" {
+		c.Header("Content-Type", m.contentType)
+	}
+	c.JSON(status, result)
+}
+
+// Abort records err on the context and stops the handler chain; the
+// middleware then writes the error response
+func Abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// Handler returns a middleware handler with the default settings
+func Handler() gin.HandlerFunc {
+	return NewMiddleware().Handler()
+}
@@ -0,0 +1,177 @@
+package ginx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kalifun/glitch/repo/gerr"
+	"github.com/kalifun/glitch/repo/gerr/internal/gerrtest"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	gerrtest.Register()
+}
+
+func serve(t *testing.T, m *Middleware, handler gin.HandlerFunc, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.Use(m.Handler())
+	router.GET("/test", handler)
+	return gerrtest.Serve(t, router, "/test", header)
+}
+
+func TestAbortWritesProblem(t *testing.T) {
+	w := serve(t, NewMiddleware(), func(c *gin.Context) {
+		Abort(c, gerr.NewError(gerrtest.NotFound).Args("42"))
+	}, map[string]string{"Accept-Language": "zh-CN,zh;q=0.9"})
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != gerr.ProblemContentType {
+		t.Errorf("Content-Type = %q, want %q", got, gerr.ProblemContentType)
+	}
+	if got := w.Header().Get("Content-Language"); got != "cn" {
+		t.Errorf("Content-Language = %q, want cn", got)
+	}
+	body := gerrtest.Decode(t, w)
+	if body["code"] != "TEST_NOT_FOUND" || body["detail"] != "未找到条目: 42" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	w := serve(t, NewMiddleware(), func(c *gin.Context) {
+		_ = c.Error(gerr.NewError(gerrtest.Busy))
+	}, nil)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+	if got := w.Header().Get("Content-Language"); got != "en" {
+		t.Errorf("Content-Language = %q, want en", got)
+	}
+}
+
+func TestPlainErrorUsesFallback(t *testing.T) {
+	w := serve(t, NewMiddleware(), func(c *gin.Context) {
+		_ = c.Error(errors.New("sql: connection refused"))
+	}, nil)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if body := gerrtest.Decode(t, w); body["code"] != "INTERNAL_ERROR" {
+		t.Errorf("code = %v, want INTERNAL_ERROR", body["code"])
+	}
+	if strings.Contains(w.Body.String(), "sql") {
+		t.Errorf("cause leaked into the response: %s", w.Body.String())
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	var hooked *gerr.Error
+	m := NewMiddleware().SetErrorHook(func(c *gin.Context, err *gerr.Error) {
+		hooked = err
+	})
+	w := serve(t, m, func(c *gin.Context) {
+		panic("boom")
+	}, nil)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if body := gerrtest.Decode(t, w); body["code"] != "INTERNAL_ERROR" {
+		t.Errorf("code = %v, want INTERNAL_ERROR", body["code"])
+	}
+	if hooked == nil || !strings.Contains(hooked.GetCause().Error(), "boom") {
+		t.Errorf("hook got %v, want the recovered panic", hooked)
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+		}
+	}()
+	serve(t, NewMiddleware(), func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	}, nil)
+}
+
+func TestWrittenResponseIsKept(t *testing.T) {
+	w := serve(t, NewMiddleware(), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+		_ = c.Error(gerr.NewError(gerrtest.NotFound).Args("42"))
+	}, nil)
+
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("response = %d %q, want 200 ok", w.Code, w.Body.String())
+	}
+}
+
+func TestCustomEngineAndContentType(t *testing.T) {
+	m := NewMiddleware().
+		SetEngine(gerr.NewProcessorEngine()).
+		SetContentType("application/json").
+		SetLanguageNegotiator(func(string) string { return "en" })
+	w := serve(t, m, func(c *gin.Context) {
+		Abort(c, gerr.NewError(gerrtest.NotFound).Args("42"))
+	}, map[string]string{"Accept-Language": "zh-CN"})
+
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	body := gerrtest.Decode(t, w)
+	if body["key"] != "test_not_found" || body["message"] != "Item not found: 42" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestAbortWithErrorKeepsStatus(t *testing.T) {
+	var hooked *gerr.Error
+	m := NewMiddleware().SetErrorHook(func(c *gin.Context, err *gerr.Error) {
+		hooked = err
+	})
+	w := serve(t, m, func(c *gin.Context) {
+		_ = c.AbortWithError(http.StatusBadRequest, errors.New("bad input"))
+	}, nil)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "INTERNAL_ERROR") {
+		t.Errorf("problem written after the status: %s", w.Body.String())
+	}
+	if hooked == nil || hooked.GetCode() != "INTERNAL_ERROR" {
+		t.Errorf("hook got %v, want the reported error", hooked)
+	}
+}
+
+func TestPanicAfterWriteIsReported(t *testing.T) {
+	var hooked *gerr.Error
+	m := NewMiddleware().SetErrorHook(func(c *gin.Context, err *gerr.Error) {
+		hooked = err
+	})
+	w := serve(t, m, func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+		c.Writer.WriteHeaderNow()
+		panic("boom")
+	}, nil)
+
+	if w.Code != http.StatusAccepted || w.Body.Len() != 0 {
+		t.Errorf("response = %d %q, want 202 without a body", w.Code, w.Body.String())
+	}
+	if hooked == nil || !strings.Contains(hooked.GetCause().Error(), "boom") {
+		t.Errorf("hook got %v, want the recovered panic", hooked)
+	}
+}
@@ -0,0 +1,152 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// LanguageMetadataKey is the metadata key carrying the requested language
+const LanguageMetadataKey = "accept-language"
+
+// gatewayLanguageKey is the key under which grpc-gateway forwards the
+// Accept-Language header
+const gatewayLanguageKey = "grpcgateway-accept-language"
+
+// UnaryServerInterceptor returns an interceptor that converts *gerr.Error
+// values returned by handlers into statuses localized in the language of the
+// incoming metadata. Only public metadata reaches the client unless the
+// converter's audience is internal. Handlers see the language through
+// gerr.LanguageFrom. Other errors are returned unchanged.
+func (c *Converter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, lang := c.incomingLanguage(ctx)
+		resp, err := handler(ctx, req)
+		return resp, c.serverError(err, lang)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor
+func (c *Converter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, lang := c.incomingLanguage(ss.Context())
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		return c.serverError(err, lang)
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor that converts the statuses
+// of failed calls back into *gerr.Error, so errors.Is matches the registered
+// definitions. The language of ctx set by gerr.WithLanguage is sent in the
+// outgoing metadata.
+func (c *Converter) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return c.clientError(invoker(outgoingLanguage(ctx), method, req, reply, cc, opts...))
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of UnaryClientInterceptor
+func (c *Converter) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		cs, err := streamer(outgoingLanguage(ctx), desc, cc, method, opts...)
+		if err != nil {
+			return nil, c.clientError(err)
+		}
+		return &clientStream{ClientStream: cs, converter: c}, nil
+	}
+}
+
+// serverError converts a *gerr.Error into a status error
+func (c *Converter) serverError(err error, lang string) error {
+	var gErr *gerr.Error
+	if err == nil || !errors.As(err, &gErr) {
+		return err
+	}
+	return c.ToStatus(gErr, lang).Err()
+}
+
+// clientError converts a status error into a *gerr.Error
+func (c *Converter) clientError(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	return c.FromError(err)
+}
+
+// incomingLanguage negotiates the language of the incoming metadata and
+// stores it in ctx
+func (c *Converter) incomingLanguage(ctx context.Context) (context.Context, string) {
+	var requested string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range []string{LanguageMetadataKey, gatewayLanguageKey} {
+			if values := md.Get(key); len(values) > 0 {
+				requested = values[0]
+				break
+			}
+		}
+	}
+	lang := c.negotiate(requested)
+	return gerr.WithLanguage(ctx, lang), lang
+}
+
+// outgoingLanguage adds the language of ctx to the outgoing metadata unless
+// the caller already set one
+func outgoingLanguage(ctx context.Context) context.Context {
+	lang, ok := gerr.LanguageFrom(ctx)
+	if !ok {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(LanguageMetadataKey)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, LanguageMetadataKey, lang)
+}
+
+// serverStream overrides the context of a server stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// clientStream converts the errors of a client stream
+type clientStream struct {
+	grpc.ClientStream
+	converter *Converter
+}
+
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, err := s.ClientStream.Header()
+	return md, s.converter.clientError(err)
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	return s.converter.clientError(s.ClientStream.RecvMsg(m))
+}
+
+// UnaryServerInterceptor returns a server interceptor using the default converter
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return defaultConverter.UnaryServerInterceptor()
+}
+
+// StreamServerInterceptor returns a server interceptor using the default converter
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return defaultConverter.StreamServerInterceptor()
+}
+
+// UnaryClientInterceptor returns a client interceptor using the default converter
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return defaultConverter.UnaryClientInterceptor()
+}
+
+// StreamClientInterceptor returns a client interceptor using the default converter
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return defaultConverter.StreamClientInterceptor()
+}
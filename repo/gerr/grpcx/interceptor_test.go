@@ -0,0 +1,198 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var userNotFound = gerr.NewError(gerr.ErrWrapper{
+	Key:      "grpcx_user_not_found",
+	Code:     "GRPCX_USER_NOT_FOUND",
+	Category: "resource",
+	Severity: gerr.SeverityError,
+	Messages: map[string]string{
+		"en": "User not found: %s",
+		"cn": "用户未找到: %s",
+	},
+	MetadataVisibility: map[string]gerr.Visibility{"user_id": gerr.VisibilityPublic},
+})
+
+// healthServer answers every call with the error of its handler
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	err      func(ctx context.Context) error
+	language string
+}
+
+func (s *healthServer) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s.language, _ = gerr.LanguageFrom(ctx)
+	return nil, s.err(ctx)
+}
+
+func (s *healthServer) Watch(_ *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	s.language, _ = gerr.LanguageFrom(stream.Context())
+	return s.err(stream.Context())
+}
+
+// dial starts srv behind the converter's server interceptors on a bufconn
+// listener and returns a client using its client interceptors
+func dial(t *testing.T, converter *Converter, srv *healthServer) grpc_health_v1.HealthClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(converter.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(converter.StreamServerInterceptor()),
+	)
+	grpc_health_v1.RegisterHealthServer(server, srv)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(converter.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(converter.StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func newConverter(t *testing.T) *Converter {
+	t.Helper()
+	registry := gerr.NewCacheRegistry()
+	if err := registry.Register(*userNotFound.GetErrWrapper()); err != nil {
+		t.Fatal(err)
+	}
+	return NewConverter().
+		SetRegistry(registry).
+		SetLanguageNegotiator(func(acceptLanguage string) string {
+			if lang := gerr.MatchLanguage(acceptLanguage, []string{"en", "cn"}); lang != "" {
+				return lang
+			}
+			return "en"
+		})
+}
+
+func userError(context.Context) error {
+	return userNotFound.Args("42").
+		With("user_id", "42").
+		With("sql", "SELECT * FROM users WHERE id = 42")
+}
+
+// checkStatus checks the error received by the client
+func checkStatus(t *testing.T, err error) {
+	t.Helper()
+	if !errors.Is(err, userNotFound) {
+		t.Fatalf("errors.Is(%v, userNotFound) = false", err)
+	}
+	var gErr *gerr.Error
+	if !errors.As(err, &gErr) || gErr.GetErrWrapper() == nil {
+		t.Fatalf("error %v was not rebuilt from the registry", err)
+	}
+	if got := gErr.Error(); got != "用户未找到: 42" {
+		t.Errorf("Error() = %q, want the localized message", got)
+	}
+	if got := status.Code(err); got != codes.NotFound {
+		t.Errorf("status code = %v, want NotFound", got)
+	}
+
+	st, _ := status.FromError(err)
+	var info *errdetails.ErrorInfo
+	var localized *errdetails.LocalizedMessage
+	for _, detail := range st.Details() {
+		switch v := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = v
+		case *errdetails.LocalizedMessage:
+			localized = v
+		}
+	}
+	if info == nil || localized == nil {
+		t.Fatalf("details = %v, want ErrorInfo and LocalizedMessage", st.Details())
+	}
+	if localized.Locale != "cn" || localized.Message != "用户未找到: 42" {
+		t.Errorf("localized message = %s %q, want cn 用户未找到: 42", localized.Locale, localized.Message)
+	}
+	if info.Metadata["user_id"] != "42" {
+		t.Errorf("public metadata = %v, want user_id", info.Metadata)
+	}
+	if _, ok := info.Metadata["sql"]; ok {
+		t.Errorf("private metadata reached the client: %v", info.Metadata)
+	}
+}
+
+func TestUnaryRoundTrip(t *testing.T) {
+	srv := &healthServer{err: userError}
+	client := dial(t, newConverter(t), srv)
+
+	ctx := gerr.WithLanguage(context.Background(), "zh-CN")
+	_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+
+	checkStatus(t, err)
+	if srv.language != "cn" {
+		t.Errorf("server language = %q, want cn", srv.language)
+	}
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	srv := &healthServer{err: userError}
+	client := dial(t, newConverter(t), srv)
+
+	ctx := gerr.WithLanguage(context.Background(), "zh-CN")
+	stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+
+	checkStatus(t, err)
+	if srv.language != "cn" {
+		t.Errorf("server language = %q, want cn", srv.language)
+	}
+}
+
+func TestInternalAudienceKeepsPrivateMetadata(t *testing.T) {
+	srv := &healthServer{err: userError}
+	client := dial(t, newConverter(t).SetAudience(gerr.AudienceInternal), srv)
+
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+
+	var gErr *gerr.Error
+	if !errors.As(err, &gErr) {
+		t.Fatalf("error %v is not a *gerr.Error", err)
+	}
+	if got := gErr.GetMetadata()["sql"]; got == nil {
+		t.Errorf("metadata = %v, want sql with the internal audience", gErr.GetMetadata())
+	}
+}
+
+func TestOtherErrorsPassThrough(t *testing.T) {
+	srv := &healthServer{err: func(context.Context) error {
+		return status.Error(codes.Unavailable, "try later")
+	}}
+	client := dial(t, newConverter(t), srv)
+
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	if got := status.Code(err); got != codes.Unavailable {
+		t.Errorf("status code = %v, want Unavailable", got)
+	}
+	if errors.Is(err, userNotFound) {
+		t.Error("unrelated status matched userNotFound")
+	}
+}
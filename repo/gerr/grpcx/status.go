@@ -0,0 +1,226 @@
+// Package grpcx converts gerr errors to and from gRPC statuses.
+package grpcx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultDomain is the ErrorInfo domain used when none is configured
+const DefaultDomain = "glitch"
+
+// categoryCodes maps error categories to their default gRPC code
+var categoryCodes = map[string]codes.Code{
+	"validation": codes.InvalidArgument,
+	"auth":       codes.Unauthenticated,
+	"permission": codes.PermissionDenied,
+	"forbidden":  codes.PermissionDenied,
+	"resource":   codes.NotFound,
+	"not_found":  codes.NotFound,
+	"conflict":   codes.AlreadyExists,
+	"business":   codes.FailedPrecondition,
+	"network":    codes.Unavailable,
+	"system":     codes.Internal,
+}
+
+// Converter converts between *gerr.Error and *status.Status
+type Converter struct {
+	registry  gerr.Registry
+	localizer gerr.Localizer
+	domain    string
+	negotiate func(acceptLanguage string) string
+	audience  gerr.Audience
+}
+
+// NewConverter creates a converter backed by the global registry
+func NewConverter() *Converter {
+	return &Converter{
+		registry:  gerr.GlobalRegistry(),
+		localizer: gerr.NewDefaultLocalizer(),
+		domain:    DefaultDomain,
+		negotiate: gerr.NegotiateLanguage,
+		audience:  gerr.AudiencePublic,
+	}
+}
+
+// SetRegistry sets the registry used to rebuild errors from statuses
+func (c *Converter) SetRegistry(registry gerr.Registry) *Converter {
+	c.registry = registry
+	return c
+}
+
+// SetLocalizer sets the localizer used for LocalizedMessage details
+func (c *Converter) SetLocalizer(localizer gerr.Localizer) *Converter {
+	c.localizer = localizer
+	return c
+}
+
+// SetDomain sets the ErrorInfo domain
+func (c *Converter) SetDomain(domain string) *Converter {
+	c.domain = domain
+	return c
+}
+
+// SetAudience sets who statuses are meant for. With gerr.AudiencePublic,
+// the default, only public metadata is copied into ErrorInfo; use
+// gerr.AudienceInternal between trusted services to keep all of it.
+func (c *Converter) SetAudience(audience gerr.Audience) *Converter {
+	c.audience = audience
+	return c
+}
+
+// SetLanguageNegotiator sets the function picking the language of server
+// interceptors from the requested one, e.g. a localizer's NegotiateLanguage
+func (c *Converter) SetLanguageNegotiator(negotiate func(acceptLanguage string) string) *Converter {
+	c.negotiate = negotiate
+	return c
+}
+
+// ToStatus converts err to a gRPC status carrying ErrorInfo and a
+// LocalizedMessage for the given language. ErrorInfo holds the metadata
+// visible to the audience of the converter. For public audiences the status
+// message is the localized one, and errors without a definition only show
+// their key instead of their cause.
+func (c *Converter) ToStatus(err *gerr.Error, language string) *status.Status {
+	message := c.localizer.LocalizeWithLanguage(language, err)
+	text := err.Error()
+	metadata := err.GetMetadata()
+	if c.audience != gerr.AudienceInternal {
+		if err.GetErrWrapper() == nil {
+			message = err.GetPublicMessage()
+		}
+		text = message
+		metadata = err.GetPublicMetadata()
+	}
+	st := status.New(Code(err), text)
+
+	info := &errdetails.ErrorInfo{
+		Reason:   err.GetCode(),
+		Domain:   c.domain,
+		Metadata: make(map[string]string),
+	}
+	for k, v := range metadata {
+		info.Metadata[k] = fmt.Sprint(v)
+	}
+
+	localized := &errdetails.LocalizedMessage{
+		Locale:  language,
+		Message: message,
+	}
+
+	withDetails, detailErr := st.WithDetails(info, localized)
+	if detailErr != nil {
+		return st
+	}
+	return withDetails
+}
+
+// FromStatus rebuilds a *gerr.Error from a gRPC status.
+// The ErrorInfo reason is looked up by code in the registry; unknown codes
+// produce an error without a definition. Errors with a definition keep the
+// LocalizedMessage, or else the status message, as their message. The returned error wraps st.Err().
+// It returns nil for an OK status.
+func (c *Converter) FromStatus(st *status.Status) *gerr.Error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	var info *errdetails.ErrorInfo
+	message := st.Message()
+	for _, detail := range st.Details() {
+		switch v := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = v
+		case *errdetails.LocalizedMessage:
+			message = v.Message
+		}
+	}
+
+	if info == nil {
+		return gerr.Wrap(st.Err(), strings.ToLower(st.Code().String())).Code(st.Code().String())
+	}
+
+	var result *gerr.Error
+	if def, ok := c.lookup(info.Reason); ok {
+		// the args are not sent: keep the rendered message
+		result = gerr.NewError(def).Wrap(st.Err()).Message(message)
+	} else {
+		result = gerr.Wrap(st.Err(), info.Reason).Code(info.Reason)
+	}
+	for k, v := range info.Metadata {
+		result = result.With(k, v)
+	}
+	return result
+}
+
+// FromError rebuilds a *gerr.Error from an error returned by a gRPC call.
+// Errors that are already *gerr.Error are returned as is.
+func (c *Converter) FromError(err error) *gerr.Error {
+	if err == nil {
+		return nil
+	}
+	var gErr *gerr.Error
+	if errors.As(err, &gErr) {
+		return gErr
+	}
+	st, _ := status.FromError(err)
+	return c.FromStatus(st)
+}
+
+// lookup finds the definition registered with the given code
+func (c *Converter) lookup(code string) (gerr.ErrWrapper, bool) {
+	if code == "" || c.registry == nil {
+		return gerr.ErrWrapper{}, false
+	}
+	for _, def := range c.registry.List() {
+		if def.Code == code {
+			return def, true
+		}
+	}
+	return gerr.ErrWrapper{}, false
+}
+
+// Code returns the gRPC code for err.
+// The grpc_code declared on the definition wins unless it is OK, which would
+// drop the error; otherwise it is derived from the category, falling back to
+// codes.Unknown.
+func Code(err *gerr.Error) codes.Code {
+	def := err.GetErrWrapper()
+	if def == nil {
+		return codes.Unknown
+	}
+	if def.GRPCCode != "" {
+		var code codes.Code
+		if jsonErr := code.UnmarshalJSON([]byte(`"` + strings.ToUpper(def.GRPCCode) + `"`)); jsonErr == nil && code != codes.OK {
+			return code
+		}
+	}
+	if code, ok := categoryCodes[def.Category]; ok {
+		return code
+	}
+	return codes.Unknown
+}
+
+var defaultConverter = NewConverter()
+
+// ToStatus converts err to a gRPC status using the default converter
+func ToStatus(err *gerr.Error, language string) *status.Status {
+	return defaultConverter.ToStatus(err, language)
+}
+
+// FromStatus rebuilds a *gerr.Error from a gRPC status using the default converter
+func FromStatus(st *status.Status) *gerr.Error {
+	return defaultConverter.FromStatus(st)
+}
+
+// FromError rebuilds a *gerr.Error from an error returned by a gRPC call
+// using the default converter
+func FromError(err error) *gerr.Error {
+	return defaultConverter.FromError(err)
+}
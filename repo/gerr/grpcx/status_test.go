@@ -0,0 +1,67 @@
+package grpcx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOKCodeIsIgnored(t *testing.T) {
+	err := gerr.NewError(gerr.ErrWrapper{Key: "ok_code", Code: "OK_CODE", Category: "resource", GRPCCode: "OK"})
+	if got := Code(err); got != codes.NotFound {
+		t.Errorf("Code = %v, want NotFound from the category", got)
+	}
+	if ToStatus(err, "en").Err() == nil {
+		t.Error("status of an error must not be OK")
+	}
+}
+
+func TestPublicStatusHidesUndefinedCause(t *testing.T) {
+	err := gerr.Wrap(errors.New("pq: password authentication failed for user admin"), "db_error")
+
+	st := NewConverter().ToStatus(err, "en")
+	if st.Message() != "db_error" {
+		t.Errorf("status message = %q, want db_error", st.Message())
+	}
+	for _, detail := range st.Details() {
+		if localized, ok := detail.(*errdetails.LocalizedMessage); ok && localized.Message != "db_error" {
+			t.Errorf("localized message = %q, want db_error", localized.Message)
+		}
+	}
+
+	internal := NewConverter().SetAudience(gerr.AudienceInternal).ToStatus(err, "en")
+	if !strings.Contains(internal.Message(), "password") {
+		t.Errorf("internal status message = %q, want the cause", internal.Message())
+	}
+}
+
+func TestPublicStatusUsesLocalizedMessage(t *testing.T) {
+	st := newConverter(t).ToStatus(userNotFound.Args("42"), "cn")
+	if st.Message() != "用户未找到: 42" {
+		t.Errorf("status message = %q, want the cn message", st.Message())
+	}
+}
+
+func TestFromStatusKeepsMessage(t *testing.T) {
+	converter := newConverter(t)
+	st := converter.ToStatus(userNotFound.Args("42"), "en")
+
+	err := converter.FromStatus(st)
+	if got := err.Error(); got != "User not found: 42" {
+		t.Errorf("Error() = %q, want the rendered message", got)
+	}
+	if got := err.Args("7").Error(); got != "User not found: 7" {
+		t.Errorf("Error() after Args = %q, want the definition rendered", got)
+	}
+
+	withoutDetails := status.New(codes.NotFound, "User not found: 42")
+	withInfo, _ := withoutDetails.WithDetails(&errdetails.ErrorInfo{Reason: "GRPCX_USER_NOT_FOUND"})
+	if got := converter.FromStatus(withInfo).Error(); got != "User not found: 42" {
+		t.Errorf("Error() = %q, want the status message", got)
+	}
+}
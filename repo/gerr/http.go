@@ -0,0 +1,85 @@
+package gerr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// InternalError is the definition HTTP integrations use for errors that are
+// not *Error and for recovered panics. A definition registered under its
+// key in the global registry takes its place.
+var InternalError = ErrWrapper{
+	Key:      "internal_error",
+	Code:     "INTERNAL_ERROR",
+	Category: "system",
+	Severity: SeverityCritical,
+	Messages: map[string]string{
+		"en": "Internal server error",
+		"cn": "服务器内部错误",
+	},
+	Description: "Internal server error",
+	HTTPStatus:  http.StatusInternalServerError,
+}
+
+// categoryHTTPStatus maps error categories to their default HTTP status
+var categoryHTTPStatus = map[string]int{
+	"validation": http.StatusBadRequest,
+	"auth":       http.StatusUnauthorized,
+	"permission": http.StatusForbidden,
+	"forbidden":  http.StatusForbidden,
+	"resource":   http.StatusNotFound,
+	"not_found":  http.StatusNotFound,
+	"conflict":   http.StatusConflict,
+	"business":   http.StatusUnprocessableEntity,
+	"network":    http.StatusBadGateway,
+	"system":     http.StatusInternalServerError,
+}
+
+// HTTPStatus returns the HTTP status code for the error.
+// The status declared on the definition wins; otherwise it is derived from
+// the category, falling back to 500 Internal Server Error.
+func (e *Error) HTTPStatus() int {
+	if e.errWrapper == nil {
+		return http.StatusInternalServerError
+	}
+	if e.errWrapper.HTTPStatus != 0 {
+		return e.errWrapper.HTTPStatus
+	}
+	if status, ok := categoryHTTPStatus[e.errWrapper.Category]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// AsError returns err as an *Error, wrapping other errors in fallback. The
+// definition registered under the key of fallback in the global registry is
+// preferred, so its messages can be customized.
+func AsError(err error, fallback ErrWrapper) *Error {
+	var gErr *Error
+	if errors.As(err, &gErr) {
+		return gErr
+	}
+	return newFallback(fallback).Wrap(err)
+}
+
+// Recovered converts a value recovered from a panic like AsError
+func Recovered(p interface{}, fallback ErrWrapper) *Error {
+	if err, ok := p.(error); ok {
+		var gErr *Error
+		if errors.As(err, &gErr) {
+			return gErr
+		}
+		return newFallback(fallback).Wrap(fmt.Errorf("panic: %w", err))
+	}
+	return newFallback(fallback).Wrap(fmt.Errorf("panic: %v", p))
+}
+
+// newFallback creates the fallback error, preferring the definition
+// registered under its key
+func newFallback(fallback ErrWrapper) *Error {
+	if def, ok := globalRegistry.Get(fallback.Key); ok {
+		return NewError(def)
+	}
+	return NewError(fallback)
+}
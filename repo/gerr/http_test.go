@@ -0,0 +1,66 @@
+package gerr
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAsError(t *testing.T) {
+	defined := NewError(templateDef).Args("42")
+	if got := AsError(Wrap(defined, "outer").Code("OUTER"), InternalError); got.GetCode() != "OUTER" {
+		t.Errorf("AsError returned %s, want the *Error itself", got.GetCode())
+	}
+
+	cause := errors.New("sql: connection refused")
+	got := AsError(cause, InternalError)
+	if got.GetCode() != "INTERNAL_ERROR" || got.HTTPStatus() != http.StatusInternalServerError {
+		t.Errorf("AsError = %s %d, want INTERNAL_ERROR 500", got.GetCode(), got.HTTPStatus())
+	}
+	if !errors.Is(got, cause) {
+		t.Error("fallback does not wrap the cause")
+	}
+}
+
+func TestRecovered(t *testing.T) {
+	defined := NewError(templateDef).Args("42")
+	tests := []struct {
+		value interface{}
+		code  string
+		cause string
+	}{
+		{"boom", "INTERNAL_ERROR", "panic: boom"},
+		{errors.New("nil map"), "INTERNAL_ERROR", "panic: nil map"},
+		{defined, "TEMPLATE_TEST", ""},
+	}
+	for _, tt := range tests {
+		got := Recovered(tt.value, InternalError)
+		if got.GetCode() != tt.code {
+			t.Errorf("Recovered(%v) code = %s, want %s", tt.value, got.GetCode(), tt.code)
+		}
+		if tt.cause != "" && !strings.Contains(got.GetCause().Error(), tt.cause) {
+			t.Errorf("Recovered(%v) cause = %v, want %q", tt.value, got.GetCause(), tt.cause)
+		}
+	}
+}
+
+func TestRegisteredFallbackWins(t *testing.T) {
+	fallback := ErrWrapper{Key: "http_test_fallback", Code: "FALLBACK", Category: "system"}
+	if err := Register(ErrWrapper{
+		Key:        "http_test_fallback",
+		Code:       "UNAVAILABLE",
+		Category:   "system",
+		HTTPStatus: http.StatusServiceUnavailable,
+		Messages:   map[string]string{"en": "Temporarily unavailable"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := AsError(errors.New("boom"), fallback); got.GetCode() != "UNAVAILABLE" {
+		t.Errorf("AsError code = %s, want the registered UNAVAILABLE", got.GetCode())
+	}
+	if got := Recovered("boom", fallback); got.GetCode() != "UNAVAILABLE" {
+		t.Errorf("Recovered code = %s, want the registered UNAVAILABLE", got.GetCode())
+	}
+}
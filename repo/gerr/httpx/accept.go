@@ -0,0 +1,85 @@
+package httpx
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+// Media types of error responses
+const (
+	MediaTypeProblem = gerr.ProblemContentType
+	MediaTypeJSON    = "application/json"
+	MediaTypeText    = "text/plain"
+)
+
+// NegotiateMediaType picks the offered media type that best matches an
+// Accept header. Higher quality values win, then more specific ranges, then
+// the order of offers. It returns the first offer when the header is empty
+// and "" when nothing is acceptable.
+func NegotiateMediaType(accept string, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best := ""
+	bestQ, bestSpec := 0.0, -1
+	for _, offer := range offers {
+		q, spec := acceptQuality(accept, offer)
+		if q > bestQ || (q == bestQ && q > 0 && spec > bestSpec) {
+			best, bestQ, bestSpec = offer, q, spec
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality of the most specific Accept range
+// matching offer, and how specific that range is: 2 for an exact type,
+// 1 for type/* and 0 for */*
+func acceptQuality(accept, offer string) (float64, int) {
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		spec := -1
+		switch {
+		case mediaType == offer:
+			spec = 2
+		case strings.HasSuffix(mediaType, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(mediaType, "*")):
+			spec = 1
+		case mediaType == "*/*":
+			spec = 0
+		}
+		if spec <= specificity {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		quality, specificity = q, spec
+	}
+	return quality, specificity
+}
+
+// formatType returns the gerr format producing a media type
+func formatType(mediaType string) gerr.FormatType {
+	switch mediaType {
+	case MediaTypeProblem:
+		return gerr.FormatTypeProblem
+	case MediaTypeText:
+		return gerr.FormatTypeText
+	}
+	return gerr.FormatTypeStructured
+}
@@ -0,0 +1,224 @@
+// Package httpx integrates gerr with net/http.
+package httpx
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+// Handler is an HTTP handler that returns an error instead of writing it
+type Handler func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls h and writes its error with the default responder
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defaultResponder.Handle(h).ServeHTTP(w, r)
+}
+
+// Responder writes errors as HTTP responses
+type Responder struct {
+	process    func(ctx context.Context, err *gerr.Error) interface{}
+	mediaTypes []string
+	negotiate  func(acceptLanguage string) string
+	fallback   gerr.ErrWrapper
+	hook       func(r *http.Request, err *gerr.Error)
+}
+
+// NewResponder creates a responder that processes errors with gerr.Process
+// and offers problem details, JSON and plain text, in that order
+func NewResponder() *Responder {
+	return &Responder{
+		process:    gerr.Process,
+		mediaTypes: []string{MediaTypeProblem, MediaTypeJSON, MediaTypeText},
+		negotiate:  gerr.NegotiateLanguage,
+		fallback:   gerr.InternalError,
+	}
+}
+
+// SetEngine processes errors with engine instead of the global one. The
+// media type is passed with gerr.WithFormatOptions, which only the default
+// formatter honors.
+func (rs *Responder) SetEngine(engine *gerr.ProcessorEngine) *Responder {
+	rs.process = engine.Process
+	return rs
+}
+
+// SetMediaTypes sets the offered media types in order of preference; the
+// first is used when the request has no Accept header or none matches
+func (rs *Responder) SetMediaTypes(mediaTypes ...string) *Responder {
+	rs.mediaTypes = append([]string(nil), mediaTypes...)
+	return rs
+}
+
+// SetLanguageNegotiator sets the function picking the response language
+// from the Accept-Language header, e.g. a localizer's NegotiateLanguage
+func (rs *Responder) SetLanguageNegotiator(negotiate func(acceptLanguage string) string) *Responder {
+	rs.negotiate = negotiate
+	return rs
+}
+
+// SetFallback sets the definition wrapping errors that are not *gerr.Error
+// and recovered panics, gerr.InternalError by default
+func (rs *Responder) SetFallback(def gerr.ErrWrapper) *Responder {
+	rs.fallback = def
+	return rs
+}
+
+// SetErrorHook sets a function called with every error before it is
+// written, e.g. to log it
+func (rs *Responder) SetErrorHook(hook func(r *http.Request, err *gerr.Error)) *Responder {
+	rs.hook = hook
+	return rs
+}
+
+// Handle adapts h to an http.Handler that writes its error. When h already
+// wrote a response the error is only passed to the error hook.
+func (rs *Responder) Handle(h Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		err := h(tw, r)
+		if err == nil {
+			return
+		}
+		if tw.written {
+			rs.report(r, gerr.AsError(err, rs.fallback))
+			return
+		}
+		rs.WriteError(w, r, err)
+	})
+}
+
+// Recover returns a middleware that turns panics of next into the fallback
+// error. http.ErrAbortHandler is re-panicked so the server aborts the
+// response as usual.
+func (rs *Responder) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			err := gerr.Recovered(p, rs.fallback)
+			if tw.written {
+				rs.report(r, err)
+				return
+			}
+			rs.write(w, r, err)
+		}()
+		next.ServeHTTP(tw, r)
+	})
+}
+
+// report passes err to the error hook, if any
+func (rs *Responder) report(r *http.Request, err *gerr.Error) {
+	if rs.hook != nil {
+		rs.hook(r, err)
+	}
+}
+
+// WriteError writes err in the media type and language negotiated from
+// the request, with the error's HTTP status
+func (rs *Responder) WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	rs.write(w, r, gerr.AsError(err, rs.fallback))
+}
+
+// write processes err and writes the response
+func (rs *Responder) write(w http.ResponseWriter, r *http.Request, err *gerr.Error) {
+	rs.report(r, err)
+
+	mediaType := NegotiateMediaType(r.Header.Get("Accept"), rs.mediaTypes)
+	if mediaType == "" && len(rs.mediaTypes) > 0 {
+		mediaType = rs.mediaTypes[0]
+	}
+	lang := rs.negotiate(r.Header.Get("Accept-Language"))
+
+	ctx := gerr.WithLanguage(r.Context(), lang)
+	ctx = gerr.WithFormatOptions(ctx, gerr.FormatOptions{
+		IncludeMetadata: true,
+		Format:          formatType(mediaType),
+		Instance:        r.URL.Path,
+		Audience:        gerr.AudiencePublic,
+	})
+	result := rs.process(ctx, err)
+
+	var body []byte
+	if text, ok := result.(string); ok {
+		body = []byte(text)
+	} else {
+		body, _ = json.Marshal(result)
+	}
+
+	header := w.Header()
+	gerr.SetRetryAfterHeader(header, err)
+	header.Set("Content-Language", lang)
+	header.Add("Vary", "Accept, Accept-Language")
+	if mediaType == MediaTypeText {
+		header.Set("Content-Type", mediaType+"; charset=utf-8")
+	} else {
+		header.Set("Content-Type", mediaType)
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(err.HTTPStatus())
+	_, _ = w.Write(body)
+}
+
+// trackingWriter records whether a response was started
+type trackingWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Hijack implements http.Hijacker, e.g. for WebSocket upgrades, when the
+// underlying writer does
+func (w *trackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("httpx: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+	w.written = true
+	return h.Hijack()
+}
+
+// Flush implements http.Flusher when the underlying writer does
+func (w *trackingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.written = true
+		f.Flush()
+	}
+}
+
+var defaultResponder = NewResponder()
+
+// WriteError writes err with the default responder
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	defaultResponder.WriteError(w, r, err)
+}
+
+// Recover returns a panic recovery middleware using the default responder
+func Recover(next http.Handler) http.Handler {
+	return defaultResponder.Recover(next)
+}
@@ -0,0 +1,220 @@
+package httpx
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"github.com/kalifun/glitch/repo/gerr/internal/gerrtest"
+)
+
+func init() {
+	gerrtest.Register()
+}
+
+func serve(t *testing.T, handler http.Handler, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	return gerrtest.Serve(t, handler, "/items/42", header)
+}
+
+func failWith(err error) Handler {
+	return func(http.ResponseWriter, *http.Request) error {
+		return err
+	}
+}
+
+func TestHandlerWritesProblem(t *testing.T) {
+	w := serve(t, failWith(gerr.NewError(gerrtest.NotFound).Args("42")),
+		map[string]string{"Accept-Language": "zh-CN,zh;q=0.9"})
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if got := w.Header().Get("Content-Type"); got != MediaTypeProblem {
+		t.Errorf("Content-Type = %q, want %q", got, MediaTypeProblem)
+	}
+	if got := w.Header().Get("Content-Language"); got != "cn" {
+		t.Errorf("Content-Language = %q, want cn", got)
+	}
+	body := gerrtest.Decode(t, w)
+	if body["code"] != "TEST_NOT_FOUND" || body["detail"] != "未找到条目: 42" || body["instance"] != "/items/42" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestMediaTypeNegotiation(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", MediaTypeProblem},
+		{"application/json", MediaTypeJSON},
+		{"text/plain, application/json;q=0.5", MediaTypeText + "; charset=utf-8"},
+		{"image/png", MediaTypeProblem},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			w := serve(t, failWith(gerr.NewError(gerrtest.NotFound).Args("42")),
+				map[string]string{"Accept": tt.accept})
+
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if w.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want 404", w.Code)
+			}
+		})
+	}
+
+	w := serve(t, failWith(gerr.NewError(gerrtest.NotFound).Args("42")), map[string]string{"Accept": "text/plain"})
+	if !strings.Contains(w.Body.String(), "Item not found: 42") {
+		t.Errorf("text body = %q", w.Body.String())
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	w := serve(t, failWith(gerr.NewError(gerrtest.Busy)), nil)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("Retry-After = %q, want 30", got)
+	}
+}
+
+func TestPlainErrorUsesFallback(t *testing.T) {
+	w := serve(t, failWith(errors.New("sql: connection refused")), nil)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if body := gerrtest.Decode(t, w); body["code"] != "INTERNAL_ERROR" {
+		t.Errorf("code = %v, want INTERNAL_ERROR", body["code"])
+	}
+	if strings.Contains(w.Body.String(), "sql") {
+		t.Errorf("cause leaked into the response: %s", w.Body.String())
+	}
+}
+
+func TestWrittenResponseCallsHook(t *testing.T) {
+	var hooked *gerr.Error
+	responder := NewResponder().SetErrorHook(func(r *http.Request, err *gerr.Error) {
+		hooked = err
+	})
+	w := serve(t, responder.Handle(func(w http.ResponseWriter, r *http.Request) error {
+		_, _ = w.Write([]byte("partial"))
+		return gerr.NewError(gerrtest.NotFound).Args("42")
+	}), nil)
+
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("response = %d %q, want 200 partial", w.Code, w.Body.String())
+	}
+	if hooked == nil || hooked.GetCode() != "TEST_NOT_FOUND" {
+		t.Errorf("hook got %v, want the handler error", hooked)
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	var hooked *gerr.Error
+	responder := NewResponder().SetErrorHook(func(r *http.Request, err *gerr.Error) {
+		hooked = err
+	})
+	w := serve(t, responder.Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})), nil)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if body := gerrtest.Decode(t, w); body["code"] != "INTERNAL_ERROR" {
+		t.Errorf("code = %v, want INTERNAL_ERROR", body["code"])
+	}
+	if hooked == nil || !strings.Contains(hooked.GetCause().Error(), "boom") {
+		t.Errorf("hook got %v, want the recovered panic", hooked)
+	}
+}
+
+func TestRecoverAbortHandler(t *testing.T) {
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+		}
+	}()
+	serve(t, Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	})), nil)
+}
+
+func TestCustomMediaTypesAndLanguage(t *testing.T) {
+	responder := NewResponder().
+		SetMediaTypes(MediaTypeJSON).
+		SetLanguageNegotiator(func(string) string { return "en" })
+	w := serve(t, responder.Handle(failWith(gerr.NewError(gerrtest.NotFound).Args("42"))),
+		map[string]string{"Accept-Language": "zh-CN", "Accept": "application/problem+json"})
+
+	if got := w.Header().Get("Content-Type"); got != MediaTypeJSON {
+		t.Errorf("Content-Type = %q, want %q", got, MediaTypeJSON)
+	}
+	body := gerrtest.Decode(t, w)
+	if body["key"] != "test_not_found" || body["message"] != "Item not found: 42" {
+		t.Errorf("body = %v", body)
+	}
+}
+
+// hijackRecorder is a recorder whose connection can be hijacked
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.conn, bufio.NewReadWriter(bufio.NewReader(w.conn), bufio.NewWriter(w.conn)), nil
+}
+
+func TestHijackThroughMiddleware(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: server}
+
+	var hooked *gerr.Error
+	responder := NewResponder().SetErrorHook(func(r *http.Request, err *gerr.Error) {
+		hooked = err
+	})
+	handler := responder.Recover(responder.Handle(func(w http.ResponseWriter, r *http.Request) error {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			t.Fatal("writer does not implement http.Hijacker")
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return gerr.NewError(gerrtest.NotFound).Args("42")
+	}))
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws", nil))
+
+	if w.Body.Len() != 0 {
+		t.Errorf("response written after hijack: %q", w.Body.String())
+	}
+	if hooked == nil {
+		t.Error("error after hijack was not reported")
+	}
+}
+
+func TestHijackUnsupported(t *testing.T) {
+	w := serve(t, Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := w.(http.Hijacker).Hijack(); err == nil {
+			t.Error("Hijack succeeded on a writer without support")
+		}
+	})), nil)
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
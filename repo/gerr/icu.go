@@ -0,0 +1,375 @@
+package gerr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/currency"
+)
+
+// Message formats of a definition
+const (
+	MessageFormatPrintf = "printf" // fmt verbs and {name} placeholders, the default
+	MessageFormatICU    = "icu"    // ICU MessageFormat
+)
+
+// MessageArgument is an argument used by an ICU message
+type MessageArgument struct {
+	Name  string
+	Type  string // "", "number", "date", "time", "plural", "selectordinal" or "select"
+	Style string // style of a number, date or time argument, e.g. "percent"
+}
+
+// icuNode kinds
+const (
+	icuText = iota
+	icuArg
+	icuPound
+)
+
+// icuNode is a part of a parsed ICU message
+type icuNode struct {
+	kind   int
+	text   string
+	name   string
+	typ    string
+	style  string
+	offset float64
+	cases  []icuCase
+}
+
+// icuCase is a branch of a plural, selectordinal or select argument
+type icuCase struct {
+	selector string
+	nodes    []icuNode
+}
+
+// icuCache holds parsed messages by source text
+var icuCache sync.Map
+
+// ParseICUMessage checks the syntax of an ICU message and returns its
+// arguments in order of first use
+func ParseICUMessage(msg string) ([]MessageArgument, error) {
+	nodes, err := parseICU(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []MessageArgument
+	seen := make(map[string]int)
+	var walk func([]icuNode)
+	walk = func(nodes []icuNode) {
+		for _, n := range nodes {
+			if n.kind != icuArg {
+				continue
+			}
+			if i, ok := seen[n.name]; ok {
+				if args[i].Type == "" {
+					args[i].Type, args[i].Style = n.typ, n.style
+				}
+			} else {
+				seen[n.name] = len(args)
+				args = append(args, MessageArgument{Name: n.name, Type: n.typ, Style: n.style})
+			}
+			for _, c := range n.cases {
+				walk(c.nodes)
+			}
+		}
+	}
+	walk(nodes)
+	return args, nil
+}
+
+// cachedICU returns the parsed message, parsing it on first use
+func cachedICU(msg string) ([]icuNode, error) {
+	if nodes, ok := icuCache.Load(msg); ok {
+		return nodes.([]icuNode), nil
+	}
+	nodes, err := parseICU(msg)
+	if err != nil {
+		return nil, err
+	}
+	icuCache.Store(msg, nodes)
+	return nodes, nil
+}
+
+// icuParser is a recursive descent parser for ICU MessageFormat
+type icuParser struct {
+	s   string
+	pos int
+}
+
+func parseICU(s string) ([]icuNode, error) {
+	p := &icuParser{s: s}
+	nodes, err := p.message(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(s) {
+		return nil, p.errorf("unexpected '}'")
+	}
+	return nodes, nil
+}
+
+func (p *icuParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid ICU message %q: %s at offset %d", p.s, fmt.Sprintf(format, args...), p.pos)
+}
+
+// message parses text and arguments up to an unmatched '}' or the end.
+// inPlural enables '#' as the number of the enclosing plural.
+func (p *icuParser) message(inPlural bool) ([]icuNode, error) {
+	var nodes []icuNode
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, icuNode{kind: icuText, text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.s) {
+		switch c := p.s[p.pos]; {
+		case c == '\'':
+			p.quoted(&text, inPlural)
+		case c == '{':
+			flush()
+			node, err := p.argument(inPlural)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		case c == '}':
+			flush()
+			return nodes, nil
+		case c == '#' && inPlural:
+			flush()
+			nodes = append(nodes, icuNode{kind: icuPound})
+			p.pos++
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+	flush()
+	return nodes, nil
+}
+
+// quoted handles an apostrophe: a doubled apostrophe is a literal one,
+// and an apostrophe before a syntax character starts quoted text
+func (p *icuParser) quoted(text *strings.Builder, inPlural bool) {
+	p.pos++
+	if p.pos < len(p.s) && p.s[p.pos] == '\'' {
+		text.WriteByte('\'')
+		p.pos++
+		return
+	}
+	if p.pos >= len(p.s) || !(p.s[p.pos] == '{' || p.s[p.pos] == '}' || (inPlural && p.s[p.pos] == '#')) {
+		text.WriteByte('\'')
+		return
+	}
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		if c != '\'' {
+			text.WriteByte(c)
+			continue
+		}
+		if p.pos < len(p.s) && p.s[p.pos] == '\'' {
+			text.WriteByte('\'')
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+// argument parses {name}, {name, type[, style]} and the complex
+// plural, selectordinal and select arguments
+func (p *icuParser) argument(inPlural bool) (icuNode, error) {
+	p.pos++ // '{'
+	p.space()
+	node := icuNode{kind: icuArg, name: p.word()}
+	if node.name == "" {
+		return node, p.errorf("missing argument name")
+	}
+	p.space()
+	if p.accept('}') {
+		return node, nil
+	}
+	if !p.accept(',') {
+		return node, p.errorf("expected ',' or '}' after argument %q", node.name)
+	}
+	p.space()
+	node.typ = p.word()
+	p.space()
+
+	switch node.typ {
+	case "number", "date", "time":
+		if p.accept(',') {
+			end := strings.IndexByte(p.s[p.pos:], '}')
+			if end < 0 {
+				return node, p.errorf("unterminated argument %q", node.name)
+			}
+			node.style = strings.TrimSpace(p.s[p.pos : p.pos+end])
+			p.pos += end
+		}
+		if !p.accept('}') {
+			return node, p.errorf("unterminated argument %q", node.name)
+		}
+		if !validStyle(node.typ, node.style) {
+			return node, p.errorf("unsupported %s style %q", node.typ, node.style)
+		}
+		return node, nil
+	case "plural", "selectordinal":
+		return node, p.cases(&node, true)
+	case "select":
+		return node, p.cases(&node, inPlural)
+	case "":
+		return node, p.errorf("missing type of argument %q", node.name)
+	}
+	return node, p.errorf("unsupported argument type %q", node.typ)
+}
+
+// cases parses the branches of a complex argument
+func (p *icuParser) cases(node *icuNode, inPlural bool) error {
+	if !p.accept(',') {
+		return p.errorf("expected ',' after %s", node.typ)
+	}
+	p.space()
+	if node.typ != "select" && strings.HasPrefix(p.s[p.pos:], "offset:") {
+		p.pos += len("offset:")
+		p.space()
+		offset, err := strconv.ParseFloat(p.selector(), 64)
+		if err != nil {
+			return p.errorf("invalid offset")
+		}
+		node.offset = offset
+	}
+
+	seen := make(map[string]bool)
+	for {
+		p.space()
+		if p.pos >= len(p.s) {
+			return p.errorf("unterminated %s of %q", node.typ, node.name)
+		}
+		if p.accept('}') {
+			break
+		}
+
+		selector := p.selector()
+		if selector == "" {
+			return p.errorf("missing selector in %s of %q", node.typ, node.name)
+		}
+		if !validSelector(node.typ, selector) {
+			return p.errorf("invalid %s selector %q", node.typ, selector)
+		}
+		if seen[selector] {
+			return p.errorf("duplicate selector %q", selector)
+		}
+		seen[selector] = true
+
+		p.space()
+		if !p.accept('{') {
+			return p.errorf("expected '{' after selector %q", selector)
+		}
+		nodes, err := p.message(inPlural)
+		if err != nil {
+			return err
+		}
+		if !p.accept('}') {
+			return p.errorf("unterminated case %q", selector)
+		}
+		node.cases = append(node.cases, icuCase{selector: selector, nodes: nodes})
+	}
+
+	if !seen[PluralOther] {
+		return p.errorf("%s of %q needs an \"other\" case", node.typ, node.name)
+	}
+	return nil
+}
+
+func (p *icuParser) space() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *icuParser) accept(c byte) bool {
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// word reads an argument name or type
+func (p *icuParser) word() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c != '_' && (c < '0' || c > '9') && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// selector reads a case selector or an offset value
+func (p *icuParser) selector() string {
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n{}", p.s[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// validSelector checks a selector: plural and selectordinal accept CLDR
+// categories and exact values such as =0, select accepts any keyword
+func validSelector(typ, selector string) bool {
+	if typ == "select" {
+		return true
+	}
+	if strings.HasPrefix(selector, "=") {
+		_, err := strconv.ParseFloat(selector[1:], 64)
+		return err == nil
+	}
+	return IsPluralCategory(selector)
+}
+
+// currencySkeleton is the style prefix of a number in a fixed currency,
+// e.g. ::currency/USD
+const currencySkeleton = "::currency/"
+
+// CurrencyCode returns the ISO 4217 code of a ::currency/XXX number style
+func CurrencyCode(style string) (string, bool) {
+	if !strings.HasPrefix(style, currencySkeleton) {
+		return "", false
+	}
+	unit, err := currency.ParseISO(strings.TrimPrefix(style, currencySkeleton))
+	if err != nil {
+		return "", false
+	}
+	return unit.String(), true
+}
+
+// validStyle checks the style of a number, date or time argument
+func validStyle(typ, style string) bool {
+	switch typ {
+	case "number":
+		switch style {
+		case "", "integer", "percent", "currency":
+			return true
+		}
+		_, ok := CurrencyCode(style)
+		return ok
+	case "date", "time":
+		switch style {
+		case "", "short", "medium", "long", "full":
+			return true
+		}
+	}
+	return false
+}
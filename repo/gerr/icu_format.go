@@ -0,0 +1,224 @@
+package gerr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// dateLayouts holds date layouts by base language and style.
+// x/text has no date formatting, so month and weekday names are only
+// spelled out for English; other languages use numeric layouts.
+var dateLayouts = map[string]map[string]string{
+	"en": {"short": "1/2/06", "medium": "Jan 2, 2006", "long": "January 2, 2006", "full": "Monday, January 2, 2006"},
+	"zh": {"short": "2006/1/2", "medium": "2006年1月2日"},
+	"ja": {"short": "2006/01/02", "medium": "2006/01/02", "long": "2006年1月2日"},
+	"ko": {"short": "06. 1. 2.", "medium": "2006. 1. 2.", "long": "2006년 1월 2일"},
+	"de": {"short": "02.01.06", "medium": "02.01.2006"},
+	"ru": {"short": "02.01.2006", "medium": "02.01.2006"},
+	"fr": {"short": "02/01/2006", "medium": "02/01/2006"},
+	"es": {"short": "2/1/06", "medium": "02/01/2006"},
+	"it": {"short": "02/01/06", "medium": "02/01/2006"},
+	"pt": {"short": "02/01/2006", "medium": "02/01/2006"},
+}
+
+// timeLayouts holds time layouts by base language and style; languages
+// without an entry use the 24-hour clock of ""
+var timeLayouts = map[string]map[string]string{
+	"":   {"short": "15:04", "medium": "15:04:05", "long": "15:04:05 MST", "full": "15:04:05 MST"},
+	"en": {"short": "3:04 PM", "medium": "3:04:05 PM", "long": "3:04:05 PM MST", "full": "3:04:05 PM MST"},
+}
+
+// icuFormatter renders a parsed ICU message in one language
+type icuFormatter struct {
+	lang    string
+	tag     language.Tag
+	printer *message.Printer
+	value   func(name string) (interface{}, bool)
+}
+
+// formatICU renders an ICU message for lang, reading argument values with
+// value. A message that fails to parse is returned unchanged.
+func formatICU(lang, msg string, value func(name string) (interface{}, bool)) string {
+	nodes, err := cachedICU(msg)
+	if err != nil {
+		return msg
+	}
+
+	tag := languageTag(lang)
+	f := &icuFormatter{
+		lang:    lang,
+		tag:     tag,
+		printer: message.NewPrinter(tag),
+		value:   value,
+	}
+	var b strings.Builder
+	f.format(&b, nodes, nil)
+	return b.String()
+}
+
+// format writes nodes to b; pound is the number of the innermost plural
+func (f *icuFormatter) format(b *strings.Builder, nodes []icuNode, pound *float64) {
+	for _, n := range nodes {
+		switch n.kind {
+		case icuText:
+			b.WriteString(n.text)
+		case icuPound:
+			if pound != nil {
+				b.WriteString(f.printer.Sprint(number.Decimal(*pound)))
+			} else {
+				b.WriteByte('#')
+			}
+		case icuArg:
+			f.argument(b, n, pound)
+		}
+	}
+}
+
+func (f *icuFormatter) argument(b *strings.Builder, n icuNode, pound *float64) {
+	v, ok := f.value(n.name)
+	if !ok {
+		b.WriteString("{" + n.name + "}")
+		return
+	}
+
+	switch n.typ {
+	case "":
+		if _, isTime := v.(time.Time); isTime {
+			b.WriteString(f.dateTime(v, "date", ""))
+			return
+		}
+		b.WriteString(f.number(v, ""))
+	case "number":
+		b.WriteString(f.number(v, n.style))
+	case "date", "time":
+		b.WriteString(f.dateTime(v, n.typ, n.style))
+	case "plural", "selectordinal":
+		num, ok := toFloat(v)
+		if !ok {
+			f.format(b, n.selectCase(PluralOther), pound)
+			return
+		}
+		rest := num - n.offset
+		selector := "=" + strconv.FormatFloat(num, 'f', -1, 64)
+		if !n.hasCase(selector) {
+			operand := v
+			if n.offset != 0 {
+				operand = rest
+			}
+			if n.typ == "plural" {
+				selector = PluralCategory(f.lang, operand)
+			} else {
+				selector = OrdinalCategory(f.lang, operand)
+			}
+		}
+		f.format(b, n.selectCase(selector), &rest)
+	case "select":
+		f.format(b, n.selectCase(fmt.Sprint(v)), pound)
+	}
+}
+
+// hasCase reports whether the argument has a case for selector
+func (n icuNode) hasCase(selector string) bool {
+	for _, c := range n.cases {
+		if c.selector == selector {
+			return true
+		}
+	}
+	return false
+}
+
+// selectCase returns the case for selector, or the "other" case
+func (n icuNode) selectCase(selector string) []icuNode {
+	var other []icuNode
+	for _, c := range n.cases {
+		if c.selector == selector {
+			return c.nodes
+		}
+		if c.selector == PluralOther {
+			other = c.nodes
+		}
+	}
+	return other
+}
+
+// number formats a numeric value with the separators of the language.
+// The currency style needs a currency.Amount, since the currency of an amount
+// does not depend on the language; plain numbers are formatted as decimals.
+// The ::currency/XXX style formats plain numbers in the given currency.
+func (f *icuFormatter) number(v interface{}, style string) string {
+	if amount, ok := v.(currency.Amount); ok {
+		return f.printer.Sprint(currency.Symbol(amount))
+	}
+	if _, isString := v.(string); isString {
+		return fmt.Sprint(v)
+	}
+	if _, ok := toFloat(v); !ok {
+		return fmt.Sprint(v)
+	}
+
+	switch style {
+	case "integer":
+		return f.printer.Sprint(number.Decimal(v, number.MaxFractionDigits(0)))
+	case "percent":
+		return f.printer.Sprint(number.Percent(v))
+	}
+	if code, ok := CurrencyCode(style); ok {
+		unit := currency.MustParseISO(code)
+		return f.printer.Sprint(currency.Symbol(unit.Amount(v)))
+	}
+	return f.printer.Sprint(number.Decimal(v))
+}
+
+// dateTime formats a time.Time with the layout of the language and style
+func (f *icuFormatter) dateTime(v interface{}, typ, style string) string {
+	t, ok := v.(time.Time)
+	if !ok {
+		if p, isPtr := v.(*time.Time); isPtr && p != nil {
+			t, ok = *p, true
+		}
+	}
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	if style == "" {
+		style = "medium"
+	}
+
+	base, _ := f.tag.Base()
+	if typ == "time" {
+		layouts, ok := timeLayouts[base.String()]
+		if !ok {
+			layouts = timeLayouts[""]
+		}
+		return t.Format(layouts[style])
+	}
+
+	layouts := dateLayouts[base.String()]
+	if layout, ok := layouts[style]; ok {
+		return t.Format(layout)
+	}
+	if layout, ok := layouts["long"]; ok && style == "full" {
+		return t.Format(layout)
+	}
+	if layout, ok := layouts["medium"]; ok {
+		return t.Format(layout)
+	}
+	return t.Format("2006-01-02")
+}
+
+// toFloat converts a numeric value or decimal string to float64
+func toFloat(v interface{}) (float64, bool) {
+	digits, ok := decimalString(v)
+	if !ok {
+		return 0, false
+	}
+	num, err := strconv.ParseFloat(digits, 64)
+	return num, err == nil
+}
@@ -0,0 +1,164 @@
+package gerr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/currency"
+)
+
+func values(m map[string]interface{}) func(string) (interface{}, bool) {
+	return func(name string) (interface{}, bool) {
+		v, ok := m[name]
+		return v, ok
+	}
+}
+
+func TestParseICUMessage(t *testing.T) {
+	args, err := ParseICUMessage("{count, plural, one {# item} other {# items}} for {user}, " +
+		"limit {limit, number, ::currency/USD}, due {due, date, short}, {user} again, " +
+		"{kind, select, admin {admin {level, number}} other {guest}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MessageArgument{
+		{Name: "count", Type: "plural"},
+		{Name: "user"},
+		{Name: "limit", Type: "number", Style: "::currency/USD"},
+		{Name: "due", Type: "date", Style: "short"},
+		{Name: "kind", Type: "select"},
+		{Name: "level", Type: "number"},
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %+v, want %+v", args, want)
+	}
+}
+
+func TestParseICUErrors(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"{}", "missing argument name"},
+		{"{name", "expected ',' or '}'"},
+		{"{name,}", "missing type"},
+		{"{name, money}", "unsupported argument type"},
+		{"{n, number, scientific}", "unsupported number style"},
+		{"{n, number, ::currency/XYZ1}", "unsupported number style"},
+		{"{d, date, tiny}", "unsupported date style"},
+		{"{n, number", "unterminated argument"},
+		{"{c, plural one {x} other {y}}", "expected ',' after plural"},
+		{"{c, plural, one {x}}", "needs an \"other\" case"},
+		{"{c, plural, few {x} lots {y} other {z}}", "invalid plural selector \"lots\""},
+		{"{c, plural, =x {x} other {y}}", "invalid plural selector \"=x\""},
+		{"{c, plural, one {x} one {y} other {z}}", "duplicate selector"},
+		{"{c, plural, offset:x other {y}}", "invalid offset"},
+		{"{c, plural, one x other {y}}", "expected '{' after selector"},
+		{"{c, plural, other {y}", "unterminated plural"},
+		{"{c, plural, other {y", "unterminated case"},
+		{"text }", "unexpected '}'"},
+	}
+	for _, tt := range tests {
+		_, err := ParseICUMessage(tt.msg)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseICUMessage(%q) error = %v, want %q", tt.msg, err, tt.want)
+		}
+	}
+}
+
+func TestFormatICU(t *testing.T) {
+	due := time.Date(2024, 3, 5, 14, 7, 9, 0, time.UTC)
+	tests := []struct {
+		name string
+		lang string
+		msg  string
+		vals map[string]interface{}
+		want string
+	}{
+		{"simple", "en", "Hello {user}", map[string]interface{}{"user": "Ann"}, "Hello Ann"},
+		{"missing value", "en", "Hello {user}", nil, "Hello {user}"},
+		{"number", "en", "{n, number}", map[string]interface{}{"n": 1500.5}, "1,500.5"},
+		{"number de", "de", "{n, number}", map[string]interface{}{"n": 1500.5}, "1.500,5"},
+		{"plain number", "en", "{n}", map[string]interface{}{"n": 1234567}, "1,234,567"},
+		{"integer", "en", "{n, number, integer}", map[string]interface{}{"n": 1500.4}, "1,500"},
+		{"percent", "en", "{p, number, percent}", map[string]interface{}{"p": 0.25}, "25%"},
+		{"currency amount", "en", "{a, number, currency}", map[string]interface{}{"a": currency.EUR.Amount(12.5)}, "€ 12.50"},
+		{"currency amount de", "de", "{a, number, currency}", map[string]interface{}{"a": currency.EUR.Amount(12.5)}, "€ 12,50"},
+		{"currency plain number", "en", "{n, number, currency}", map[string]interface{}{"n": 1500.5}, "1,500.5"},
+		{"currency skeleton", "en", "{n, number, ::currency/USD}", map[string]interface{}{"n": 1500.5}, "$ 1,500.50"},
+		{"currency skeleton cn", "cn", "{n, number, ::currency/USD}", map[string]interface{}{"n": 1500.5}, "US$ 1,500.50"},
+		{"string number", "en", "{n, number}", map[string]interface{}{"n": "n/a"}, "n/a"},
+		{"date", "en", "{d, date}", map[string]interface{}{"d": due}, "Mar 5, 2024"},
+		{"date full", "en", "{d, date, full}", map[string]interface{}{"d": due}, "Tuesday, March 5, 2024"},
+		{"date cn", "cn", "{d, date}", map[string]interface{}{"d": due}, "2024年3月5日"},
+		{"date untyped", "en", "{d}", map[string]interface{}{"d": due}, "Mar 5, 2024"},
+		{"time short", "en", "{d, time, short}", map[string]interface{}{"d": due}, "2:07 PM"},
+		{"time de", "de", "{d, time, short}", map[string]interface{}{"d": due}, "14:07"},
+		{"date pointer", "en", "{d, date, short}", map[string]interface{}{"d": &due}, "3/5/24"},
+		{"plural one", "en", "{c, plural, one {# item} other {# items}}", map[string]interface{}{"c": 1}, "1 item"},
+		{"plural other", "en", "{c, plural, one {# item} other {# items}}", map[string]interface{}{"c": 1200}, "1,200 items"},
+		{"plural exact", "en", "{c, plural, =0 {no items} one {# item} other {# items}}", map[string]interface{}{"c": 0}, "no items"},
+		{"plural ru few", "ru", "{c, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", map[string]interface{}{"c": 3}, "3 файла"},
+		{"plural not a number", "en", "{c, plural, one {one} other {other}}", map[string]interface{}{"c": "x"}, "other"},
+		{"offset", "en", "{c, plural, offset:1 =0 {nobody} =1 {you} one {you and # other} other {you and # others}}",
+			map[string]interface{}{"c": 2}, "you and 1 other"},
+		{"offset exact", "en", "{c, plural, offset:1 =0 {nobody} =1 {you} one {you and # other} other {you and # others}}",
+			map[string]interface{}{"c": 1}, "you"},
+		{"offset other", "en", "{c, plural, offset:1 =0 {nobody} =1 {you} one {you and # other} other {you and # others}}",
+			map[string]interface{}{"c": 4}, "you and 3 others"},
+		{"ordinal", "en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]interface{}{"n": 22}, "22nd"},
+		{"select", "en", "{g, select, female {she} male {he} other {they}}", map[string]interface{}{"g": "female"}, "she"},
+		{"select other", "en", "{g, select, female {she} male {he} other {they}}", map[string]interface{}{"g": "x"}, "they"},
+		{"nested pound", "en", "{c, plural, other {{g, select, admin {# admins} other {# users}}}}",
+			map[string]interface{}{"c": 5, "g": "admin"}, "5 admins"},
+		{"pound outside plural", "en", "item #{n}", map[string]interface{}{"n": 3}, "item #3"},
+		{"quoted braces", "en", "'{'literal'}' {n}", map[string]interface{}{"n": 1}, "{literal} 1"},
+		{"doubled apostrophe", "en", "it''s {n}", map[string]interface{}{"n": 1}, "it's 1"},
+		{"lone apostrophe", "en", "it's", nil, "it's"},
+		{"quoted pound", "en", "{c, plural, other {'#' #}}", map[string]interface{}{"c": 2}, "# 2"},
+		{"apostrophe in quote", "en", "'{a''b}'", nil, "{a'b}"},
+		{"invalid message", "en", "{broken", nil, "{broken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatICU(tt.lang, tt.msg, values(tt.vals)); got != tt.want {
+				t.Errorf("formatICU(%s, %q) = %q, want %q", tt.lang, tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrencyCode(t *testing.T) {
+	tests := []struct {
+		style string
+		code  string
+		ok    bool
+	}{
+		{"::currency/USD", "USD", true},
+		{"::currency/CNY", "CNY", true},
+		{"::currency/usd", "USD", true},
+		{"::currency/", "", false},
+		{"currency", "", false},
+	}
+	for _, tt := range tests {
+		code, ok := CurrencyCode(tt.style)
+		if code != tt.code || ok != tt.ok {
+			t.Errorf("CurrencyCode(%q) = %q %v, want %q %v", tt.style, code, ok, tt.code, tt.ok)
+		}
+	}
+}
+
+func TestICUError(t *testing.T) {
+	def := ErrWrapper{
+		Key:           "icu_test",
+		Code:          "ICU_TEST",
+		MessageFormat: MessageFormatICU,
+		Messages:      map[string]string{"en": "{0} has {count, plural, one {# item} other {# items}}"},
+	}
+	err := NewError(def).Args("Cart").Params(map[string]interface{}{"count": 3})
+	if got := err.Error(); got != "Cart has 3 items" {
+		t.Errorf("Error() = %q", got)
+	}
+}
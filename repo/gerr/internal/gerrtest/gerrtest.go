@@ -0,0 +1,73 @@
+// Package gerrtest holds the fixtures shared by the tests of the HTTP
+// integrations.
+package gerrtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+var (
+	// NotFound is a 404 definition with one argument, in en and cn
+	NotFound = gerr.ErrWrapper{
+		Key:        "test_not_found",
+		Code:       "TEST_NOT_FOUND",
+		Category:   "resource",
+		Severity:   gerr.SeverityError,
+		HTTPStatus: http.StatusNotFound,
+		Messages: map[string]string{
+			"en": "Item not found: %s",
+			"cn": "未找到条目: %s",
+		},
+	}
+	// Busy is a retryable 503 definition with a 30s Retry-After
+	Busy = gerr.ErrWrapper{
+		Key:        "test_busy",
+		Code:       "TEST_BUSY",
+		Category:   "system",
+		Severity:   gerr.SeverityWarning,
+		HTTPStatus: http.StatusServiceUnavailable,
+		Retryable:  true,
+		RetryAfter: 30 * time.Second,
+		Messages: map[string]string{
+			"en": "Service busy",
+			"cn": "服务繁忙",
+		},
+	}
+)
+
+// Register registers the fixtures and defs in the global registry
+func Register(defs ...gerr.ErrWrapper) {
+	for _, def := range append([]gerr.ErrWrapper{NotFound, Busy}, defs...) {
+		if err := gerr.Register(def); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Serve sends a GET request with header to handler and records the response
+func Serve(t *testing.T, handler http.Handler, path string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+// Decode decodes the JSON body of w
+func Decode(t *testing.T, w *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON body %q: %v", w.Body.String(), err)
+	}
+	return body
+}
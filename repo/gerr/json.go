@@ -0,0 +1,177 @@
+package gerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WireVersion is the version of the JSON wire format written by MarshalJSON
+const WireVersion = 1
+
+// wireError is the JSON wire format of an Error.
+// A cause that is not an *Error is written with only its message; an
+// *ErrorList cause also carries its members in Errors.
+type wireError struct {
+	Version    int                    `json:"version,omitempty"`
+	Field      string                 `json:"field,omitempty"` // field path of an ErrorList member
+	Key        string                 `json:"key,omitempty"`
+	Code       string                 `json:"code,omitempty"`
+	Message    string                 `json:"message"`
+	Args       []interface{}          `json:"args,omitempty"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	Visibility map[string]Visibility  `json:"visibility,omitempty"`
+	Definition string                 `json:"definition,omitempty"`
+	Time       *time.Time             `json:"time,omitempty"`
+	Cause      *wireError             `json:"cause,omitempty"`
+	Errors     []*wireError           `json:"errors,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (e *Error) MarshalJSON() ([]byte, error) {
+	w := e.toWire()
+	w.Version = WireVersion
+	return json.Marshal(w)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// Definitions are looked up in the global registry; see UnmarshalError.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	decoded, err := UnmarshalError(data, globalRegistry)
+	if err != nil {
+		return err
+	}
+	*e = *decoded
+	return nil
+}
+
+// UnmarshalError decodes an Error written by MarshalJSON and rehydrates its
+// definition, and those of its causes, from registry by key. When a definition
+// is unknown the error keeps its key, code, args and the original message.
+func UnmarshalError(data []byte, registry Registry) (*Error, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var w wireError
+	if err := decoder.Decode(&w); err != nil {
+		return nil, err
+	}
+	if w.Version > WireVersion {
+		return nil, fmt.Errorf("unsupported error wire version: %d", w.Version)
+	}
+	return w.toError(registry), nil
+}
+
+func (e *Error) toWire() *wireError {
+	w := &wireError{
+		Key:        e.key,
+		Code:       e.code,
+		Message:    e.Error(),
+		Args:       e.args,
+		Params:     e.params,
+		Metadata:   e.metadata,
+		Visibility: e.visibility,
+	}
+	if !e.time.IsZero() {
+		t := e.time
+		w.Time = &t
+	}
+	if e.errWrapper != nil {
+		w.Definition = e.errWrapper.Key
+	}
+
+	switch cause := e.cause.(type) {
+	case nil:
+	case *Error:
+		w.Cause = cause.toWire()
+	case *ErrorList:
+		w.Cause = &wireError{Message: cause.Error()}
+		for _, fe := range cause.errs {
+			member := fe.Err.toWire()
+			member.Field = fe.Field
+			w.Cause.Errors = append(w.Cause.Errors, member)
+		}
+	default:
+		w.Cause = &wireError{Message: cause.Error()}
+	}
+	return w
+}
+
+func (w *wireError) toError(registry Registry) *Error {
+	e := &Error{
+		key:      w.Key,
+		code:     w.Code,
+		args:     decodeNumbers(w.Args),
+		metadata: make(map[string]interface{}, len(w.Metadata)),
+	}
+	if w.Params != nil {
+		e.params = make(map[string]interface{}, len(w.Params))
+		for k, v := range w.Params {
+			e.params[k] = decodeNumber(v)
+		}
+	}
+	for k, v := range w.Metadata {
+		e.metadata[k] = decodeNumber(v)
+	}
+	if w.Visibility != nil {
+		e.visibility = w.Visibility
+	}
+	if w.Time != nil {
+		e.time = *w.Time
+	}
+
+	if w.Definition != "" && registry != nil {
+		if def, ok := registry.Get(w.Definition); ok {
+			def = def.clone()
+			e.errWrapper = &def
+		}
+	}
+	if e.errWrapper == nil {
+		e.message = w.Message
+	}
+
+	switch {
+	case w.Cause == nil:
+	case len(w.Cause.Errors) > 0:
+		list := &ErrorList{}
+		for _, member := range w.Cause.Errors {
+			list.AddField(member.Field, member.toError(registry))
+		}
+		e.cause = list
+	case w.Cause.Key == "" && w.Cause.Code == "":
+		e.cause = errors.New(w.Cause.Message)
+	default:
+		e.cause = w.Cause.toError(registry)
+	}
+	return e
+}
+
+func decodeNumbers(values []interface{}) []interface{} {
+	if values == nil {
+		return nil
+	}
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = decodeNumber(v)
+	}
+	return result
+}
+
+// decodeNumber turns json.Number into int64 or float64 so that decoded
+// args work with the fmt verbs of the message
+func decodeNumber(v interface{}) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return n.String()
+}
@@ -0,0 +1,235 @@
+package gerr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var wireTime = time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+var (
+	wireNotFound = ErrWrapper{
+		Key:      "wire_not_found",
+		Code:     "WIRE_NOT_FOUND",
+		Category: "resource",
+		Severity: SeverityError,
+		Messages: map[string]string{"en": "Order not found: %s"},
+	}
+	wireInvalid = ErrWrapper{
+		Key:      "wire_invalid",
+		Code:     "WIRE_INVALID",
+		Category: "validation",
+		Severity: SeverityError,
+		Messages: map[string]string{"en": "Invalid {field}"},
+	}
+)
+
+func wireRegistry(t *testing.T) Registry {
+	t.Helper()
+	registry := NewCacheRegistry()
+	for _, def := range []ErrWrapper{wireNotFound, wireInvalid} {
+		if err := registry.Register(def); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return registry
+}
+
+// fixed returns err with a fixed time, also on its causes, so the output
+// is stable
+func fixed(err *Error) *Error {
+	result := err.clone()
+	result.time = wireTime
+	switch cause := result.cause.(type) {
+	case *Error:
+		result.cause = fixed(cause)
+	case *ErrorList:
+		list := &ErrorList{}
+		for _, fe := range cause.errs {
+			list.AddField(fe.Field, fixed(fe.Err))
+		}
+		result.cause = list
+	}
+	return result
+}
+
+func wireCases() map[string]*Error {
+	return map[string]*Error{
+		"defined": NewError(wireNotFound).
+			Args("o-1").
+			With("order_id", "o-1", VisibilityPublic).
+			With("attempt", 2),
+		"cause_chain": NewError(wireNotFound).
+			Args("o-2").
+			Wrap(NewError(wireInvalid).
+				Params(map[string]interface{}{"field": "id"}).
+				Wrap(errors.New("sql: no rows"))),
+		"list": NewError(wireInvalid).
+			Params(map[string]interface{}{"field": "order"}).
+			Wrap(Multi().
+				AddField("id", NewError(wireInvalid).Params(map[string]interface{}{"field": "id"})).
+				Add(NewError(wireNotFound).Args("o-3"))),
+	}
+}
+
+func golden(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+func TestMarshalGolden(t *testing.T) {
+	for name, err := range wireCases() {
+		t.Run(name, func(t *testing.T) {
+			data, marshalErr := json.Marshal(fixed(err))
+			if marshalErr != nil {
+				t.Fatal(marshalErr)
+			}
+			var got bytes.Buffer
+			if indentErr := json.Indent(&got, data, "", "  "); indentErr != nil {
+				t.Fatal(indentErr)
+			}
+			got.WriteByte('\n')
+
+			path := golden(name)
+			if *update {
+				if writeErr := os.WriteFile(path, got.Bytes(), 0o644); writeErr != nil {
+					t.Fatal(writeErr)
+				}
+			}
+			want, readErr := os.ReadFile(path)
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("wire format of %s changed:\n%s\nwant:\n%s", name, got.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalGolden(t *testing.T) {
+	registry := wireRegistry(t)
+	for name, err := range wireCases() {
+		t.Run(name, func(t *testing.T) {
+			data, readErr := os.ReadFile(golden(name))
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			decoded, decodeErr := UnmarshalError(data, registry)
+			if decodeErr != nil {
+				t.Fatal(decodeErr)
+			}
+
+			if decoded.GetKey() != err.GetKey() || decoded.GetCode() != err.GetCode() {
+				t.Errorf("decoded %s/%s, want %s/%s", decoded.GetKey(), decoded.GetCode(), err.GetKey(), err.GetCode())
+			}
+			if decoded.GetErrWrapper() == nil {
+				t.Error("definition was not rehydrated")
+			}
+			if got, want := decoded.Error(), err.Error(); got != want {
+				t.Errorf("message = %q, want %q", got, want)
+			}
+			if !decoded.GetTime().Equal(wireTime) {
+				t.Errorf("time = %v, want %v", decoded.GetTime(), wireTime)
+			}
+			if !errors.Is(decoded, err) {
+				t.Error("errors.Is does not match the original")
+			}
+		})
+	}
+}
+
+func TestUnmarshalGoldenDetails(t *testing.T) {
+	registry := wireRegistry(t)
+
+	data, _ := os.ReadFile(golden("defined"))
+	decoded, err := UnmarshalError(data, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.GetMetadata()["attempt"]; got != int64(2) {
+		t.Errorf("attempt = %#v, want int64(2)", got)
+	}
+	if got := decoded.GetPublicMetadata(); len(got) != 1 || got["order_id"] != "o-1" {
+		t.Errorf("public metadata = %v, want only order_id", got)
+	}
+
+	data, _ = os.ReadFile(golden("cause_chain"))
+	decoded, err = UnmarshalError(data, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(decoded, NewError(wireInvalid)) {
+		t.Error("errors.Is does not match the wrapped definition")
+	}
+	var cause *Error
+	if !errors.As(decoded.GetCause(), &cause) {
+		t.Fatalf("cause is %T, want *Error", decoded.GetCause())
+	}
+	if got := cause.GetCause(); got == nil || got.Error() != "sql: no rows" {
+		t.Errorf("root cause = %v, want sql: no rows", got)
+	}
+
+	data, _ = os.ReadFile(golden("list"))
+	decoded, err = UnmarshalError(data, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, ok := decoded.GetCause().(*ErrorList)
+	if !ok {
+		t.Fatalf("cause is %T, want *ErrorList", decoded.GetCause())
+	}
+	members := list.Errors()
+	if len(members) != 2 || members[0].Field != "id" || members[1].Field != "" {
+		t.Fatalf("members = %v, want id and an untagged error", members)
+	}
+	if !errors.Is(decoded, NewError(wireNotFound)) {
+		t.Error("errors.Is does not match a list member")
+	}
+}
+
+func TestUnmarshalUnknownDefinition(t *testing.T) {
+	data, err := os.ReadFile(golden("defined"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := UnmarshalError(data, NewCacheRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.GetErrWrapper() != nil {
+		t.Error("unknown definition was rehydrated")
+	}
+	if decoded.GetKey() != "wire_not_found" || decoded.GetCode() != "WIRE_NOT_FOUND" {
+		t.Errorf("decoded %s/%s, want wire_not_found/WIRE_NOT_FOUND", decoded.GetKey(), decoded.GetCode())
+	}
+	if got := decoded.Error(); got != "Order not found: o-1" {
+		t.Errorf("message = %q, want the original message", got)
+	}
+	if got := decoded.GetArgs(); len(got) != 1 || got[0] != "o-1" {
+		t.Errorf("args = %v, want [o-1]", got)
+	}
+}
+
+func TestUnmarshalUnsupportedVersion(t *testing.T) {
+	data, err := os.ReadFile(golden("unsupported_version"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UnmarshalError(data, wireRegistry(t)); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+
+	var decoded Error
+	if err := json.Unmarshal(data, &decoded); err == nil {
+		t.Error("expected json.Unmarshal to reject an unsupported version")
+	}
+}
@@ -0,0 +1,123 @@
+package gerr
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage is the language used when none is requested or matched
+const DefaultLanguage = "en"
+
+// languageKey is the context key for the requested language
+type languageKey struct{}
+
+// WithLanguage returns a copy of ctx carrying the requested language,
+// e.g. "cn" or a BCP 47 tag such as "zh-Hans-CN"
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// legacyLanguageKey is the bare string key read before WithLanguage existed.
+//
+// Deprecated: kept for one release so that contexts built with
+// context.WithValue(ctx, "language", lang) keep working; use WithLanguage.
+const legacyLanguageKey = "language"
+
+// LanguageFrom returns the language stored in ctx by WithLanguage. A string
+// stored under the bare "language" key is still honored as a deprecated
+// fallback and will be ignored in the next release.
+func LanguageFrom(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	if lang, ok := ctx.Value(languageKey{}).(string); ok && lang != "" {
+		return lang, true
+	}
+	lang, ok := ctx.Value(legacyLanguageKey).(string)
+	return lang, ok && lang != ""
+}
+
+// MatchLanguage picks the supported language that best matches an
+// Accept-Language header or a single language tag. Supported languages may
+// use aliases such as "cn" for Chinese. It returns "" when nothing matches.
+func MatchLanguage(acceptLanguage string, supported []string) string {
+	if acceptLanguage == "" || len(supported) == 0 {
+		return ""
+	}
+	for _, lang := range supported {
+		if lang == acceptLanguage {
+			return lang
+		}
+	}
+
+	desired := acceptedTags(acceptLanguage)
+	if len(desired) == 0 {
+		return ""
+	}
+
+	tags := make([]language.Tag, len(supported))
+	for i, lang := range supported {
+		tags[i] = languageTag(lang)
+	}
+	_, index, confidence := language.NewMatcher(tags).Match(desired...)
+	if confidence == language.No {
+		return ""
+	}
+	return supported[index]
+}
+
+// acceptedTags returns the tags of an Accept-Language header by decreasing
+// quality. Unlike language.ParseAcceptLanguage, an unknown or malformed
+// entry only drops itself, and aliases such as "cn" are resolved.
+func acceptedTags(acceptLanguage string) []language.Tag {
+	type entry struct {
+		tag     language.Tag
+		quality float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			v, err := strconv.ParseFloat(strings.TrimSpace(q), 64)
+			if err != nil {
+				continue
+			}
+			quality = v
+		}
+		tag := languageTag(strings.TrimSpace(name))
+		if tag == language.Und || quality <= 0 {
+			continue
+		}
+		entries = append(entries, entry{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].quality > entries[j].quality
+	})
+	tags := make([]language.Tag, len(entries))
+	for i, e := range entries {
+		tags[i] = e.tag
+	}
+	return tags
+}
+
+// NegotiateLanguage picks the language of the global registry's messages
+// that best matches an Accept-Language header, falling back to DefaultLanguage
+func NegotiateLanguage(acceptLanguage string) string {
+	return NewDefaultLocalizer().NegotiateLanguage(acceptLanguage)
+}
+
+// sortLanguages sorts languages with DefaultLanguage first
+func sortLanguages(languages []string) {
+	sort.Slice(languages, func(i, j int) bool {
+		if languages[i] == DefaultLanguage || languages[j] == DefaultLanguage {
+			return languages[i] == DefaultLanguage
+		}
+		return languages[i] < languages[j]
+	})
+}
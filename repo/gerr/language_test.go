@@ -0,0 +1,99 @@
+package gerr
+
+import (
+	"context"
+	"testing"
+)
+
+var languageDef = ErrWrapper{
+	Key:      "language_test",
+	Code:     "LANGUAGE_TEST",
+	Category: "resource",
+	Severity: SeverityError,
+	Messages: map[string]string{
+		"en": "Not found",
+		"cn": "未找到",
+	},
+}
+
+func TestLocalizeLegacyLanguageKey(t *testing.T) {
+	registry := NewCacheRegistry()
+	if err := registry.Register(languageDef); err != nil {
+		t.Fatal(err)
+	}
+	localizer := NewDefaultLocalizer()
+	localizer.SetRegistry(registry)
+	err := NewError(languageDef)
+
+	legacy := context.WithValue(context.Background(), legacyLanguageKey, "cn")
+	if got := localizer.Localize(legacy, err); got != "未找到" {
+		t.Errorf("bare language key: got %q, want 未找到", got)
+	}
+	if got := localizer.Localize(WithLanguage(legacy, "en"), err); got != "Not found" {
+		t.Errorf("WithLanguage over bare key: got %q, want Not found", got)
+	}
+}
+
+func TestMatchLanguage(t *testing.T) {
+	supported := []string{"en", "cn", "fr"}
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"zh-Hans-CN", "cn"},
+		{"zh-CN,zh;q=0.9", "cn"},
+		{"zh-TW", "cn"},
+		{"en-GB", "en"},
+		{"en-US,en;q=0.9", "en"},
+		{"cn", "cn"},
+		{"fr-CA", "fr"},
+		{"de;q=0.9, fr;q=0.8, en;q=0.5", "fr"},
+		{"en;q=0.2, cn;q=0.9", "cn"},
+		{"ja, ko", ""},
+		{"xx, en;q=0.5", "en"},
+		{"en;q=0, fr;q=0.1", "fr"},
+		{"en;q=bad, fr;q=0.1", "fr"},
+		{"*", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := MatchLanguage(tt.accept, supported); got != tt.want {
+			t.Errorf("MatchLanguage(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+	if got := MatchLanguage("en", nil); got != "" {
+		t.Errorf("MatchLanguage without supported languages = %q, want empty", got)
+	}
+}
+
+func TestNegotiateLanguage(t *testing.T) {
+	registry := NewCacheRegistry()
+	if err := registry.Register(languageDef); err != nil {
+		t.Fatal(err)
+	}
+	localizer := NewDefaultLocalizer()
+	localizer.SetRegistry(registry)
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"zh-Hans-CN", "cn"},
+		{"en-GB", "en"},
+		{"fr-FR, zh;q=0.8, en;q=0.5", "cn"},
+		{"ja", DefaultLanguage},
+		{"", DefaultLanguage},
+		{"not a tag!", DefaultLanguage},
+	}
+	for _, tt := range tests {
+		if got := localizer.NegotiateLanguage(tt.accept); got != tt.want {
+			t.Errorf("NegotiateLanguage(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+
+	strict := NewDefaultLocalizer().SetLanguagePolicy(LanguagePolicy{DefaultLanguage: "cn"})
+	strict.SetRegistry(registry)
+	if got := strict.NegotiateLanguage("ja"); got != "cn" {
+		t.Errorf("NegotiateLanguage with default cn = %q, want cn", got)
+	}
+}
@@ -2,12 +2,24 @@ package gerr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
 // DefaultLocalizer is the default implementation of Localizer.
 type DefaultLocalizer struct {
-	registry Registry
+	registry     Registry
+	policy       *LanguagePolicy
+	fallbackHook func(FallbackEvent)
+}
+
+// FallbackEvent describes a message served in another language than the
+// requested one
+type FallbackEvent struct {
+	Key       string
+	Code      string
+	Requested string
+	Used      string // empty when no message was found in strict mode
 }
 
 // NewDefaultLocalizer creates a new default localizer
@@ -22,49 +34,80 @@ func (l *DefaultLocalizer) SetRegistry(registry Registry) {
 	l.registry = registry
 }
 
+// SetLanguagePolicy sets the fallback policy of the localizer.
+// Without one the global policy set by SetLanguagePolicy is used.
+func (l *DefaultLocalizer) SetLanguagePolicy(policy LanguagePolicy) *DefaultLocalizer {
+	l.policy = &policy
+	return l
+}
+
+// SetFallbackHook sets a function called whenever a message is served in
+// another language than requested, e.g. to count missing translations
+func (l *DefaultLocalizer) SetFallbackHook(hook func(FallbackEvent)) *DefaultLocalizer {
+	l.fallbackHook = hook
+	return l
+}
+
+// languagePolicy returns the policy in effect for the localizer
+func (l *DefaultLocalizer) languagePolicy() LanguagePolicy {
+	if l.policy != nil {
+		return *l.policy
+	}
+	return GetLanguagePolicy()
+}
+
 // Localize returns a localized message for the given error
 func (l *DefaultLocalizer) Localize(ctx context.Context, err *Error) string {
 	// Try to get language from context
-	lang := "en" // default
-	if v := ctx.Value("language"); v != nil {
-		if langStr, ok := v.(string); ok {
-			lang = langStr
-		}
+	lang := l.languagePolicy().defaultLanguage(l.registry)
+	if v, ok := LanguageFrom(ctx); ok {
+		lang = v
 	}
 
 	return l.LocalizeWithLanguage(lang, err)
 }
 
-// LocalizeWithLanguage returns a localized message for a specific language
-func (l *DefaultLocalizer) LocalizeWithLanguage(language string, err *Error) string {
-	if err.errWrapper != nil {
-		// Use predefined error definition
-		if msg, ok := err.errWrapper.Messages[language]; ok {
-			if len(err.args) > 0 {
-				return fmt.Sprintf(msg, err.args...)
-			}
-			return msg
-		}
+// NegotiateLanguage picks the supported language that best matches an
+// Accept-Language header, falling back to the default language
+func (l *DefaultLocalizer) NegotiateLanguage(acceptLanguage string) string {
+	if lang := MatchLanguage(acceptLanguage, l.GetSupportedLanguages()); lang != "" {
+		return lang
+	}
+	return l.languagePolicy().defaultLanguage(l.registry)
+}
 
-		// Fallback to English
-		if msg, ok := err.errWrapper.Messages["en"]; ok {
-			if len(err.args) > 0 {
-				return fmt.Sprintf(msg, err.args...)
-			}
-			return msg
-		}
+// LocalizeWithLanguage returns a localized message for a specific language.
+// Missing messages follow the language policy; in strict mode a marker is
+// returned instead of an arbitrary translation.
+func (l *DefaultLocalizer) LocalizeWithLanguage(language string, err *Error) string {
+	msg, localizeErr := l.LocalizeStrict(language, err)
+	if errors.Is(localizeErr, ErrMissingTranslation) {
+		return err.missingMessage(language)
+	}
+	return msg
+}
 
-		// Fallback to first available
-		for _, msg := range err.errWrapper.Messages {
-			if len(err.args) > 0 {
-				return fmt.Sprintf(msg, err.args...)
-			}
-			return msg
-		}
+// LocalizeStrict is like LocalizeWithLanguage but reports a missing
+// message in strict mode as ErrMissingTranslation
+func (l *DefaultLocalizer) LocalizeStrict(language string, err *Error) (string, error) {
+	if err.errWrapper == nil {
+		// Fallback to error string
+		return err.Error(), nil
 	}
 
-	// Fallback to error string
-	return err.Error()
+	msg, used, ok := err.resolveTemplate(language, l.languagePolicy(), l.registry)
+	if used != language && l.fallbackHook != nil {
+		l.fallbackHook(FallbackEvent{
+			Key:       err.key,
+			Code:      err.code,
+			Requested: language,
+			Used:      used,
+		})
+	}
+	if !ok {
+		return "", fmt.Errorf("%w: %s has no %s message", ErrMissingTranslation, err.key, language)
+	}
+	return err.render(used, msg), nil
 }
 
 // GetSupportedLanguages returns all supported languages
@@ -81,6 +124,7 @@ func (l *DefaultLocalizer) GetSupportedLanguages() []string {
 	for lang := range languages {
 		result = append(result, lang)
 	}
+	sortLanguages(result)
 
 	return result
 }
@@ -0,0 +1,121 @@
+package gerr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// placeholderPattern matches named placeholders such as {user_id}
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Params returns a copy of the error with named placeholder values.
+// Messages like "User {user_id} not found in {tenant}" are rendered with
+// these values; they are merged with any params already set.
+func (e *Error) Params(params map[string]interface{}) *Error {
+	newE := e.derive()
+	merged := make(map[string]interface{}, len(e.params)+len(params))
+	for k, v := range e.params {
+		merged[k] = v
+	}
+	for k, v := range params {
+		merged[k] = v
+	}
+	newE.params = merged
+	if e.errWrapper != nil {
+		newE.message = ""
+	}
+	return newE
+}
+
+// GetParams returns the named placeholder values
+func (e *Error) GetParams() map[string]interface{} {
+	result := make(map[string]interface{}, len(e.params))
+	for k, v := range e.params {
+		result[k] = v
+	}
+	return result
+}
+
+// hasValues reports whether the message needs rendering with args or params
+func (e *Error) hasValues() bool {
+	return len(e.args) > 0 || len(e.params) > 0
+}
+
+// render fills msg, a message template in lang, with the error's named
+// params and positional args. Placeholders without a value are left untouched.
+func (e *Error) render(lang, msg string) string {
+	if e.errWrapper != nil && e.errWrapper.MessageFormat == MessageFormatICU {
+		return formatICU(lang, msg, e.icuValue)
+	}
+	if len(e.params) > 0 {
+		msg = placeholderPattern.ReplaceAllStringFunc(msg, func(m string) string {
+			name := m[1 : len(m)-1]
+			v, ok := e.params[name]
+			if !ok {
+				return m
+			}
+			if len(e.args) > 0 {
+				// the args are applied next: keep % in values literal
+				return strings.ReplaceAll(fmt.Sprint(v), "%", "%%")
+			}
+			return fmt.Sprint(v)
+		})
+	}
+	if len(e.args) > 0 {
+		args := e.args
+		if e.errWrapper != nil && len(e.errWrapper.Plurals) > 0 {
+			// a plural form such as "One failed attempt" may use fewer
+			// args than the other form
+			args = args[:usedArgs(msg, len(args))]
+		}
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// usedArgs returns how many of n args the fmt format string consumes.
+// Formats with explicit argument indexes such as %[2]d use all of them,
+// since fmt does not report extra args for them.
+func usedArgs(format string, n int) int {
+	used := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		if i < len(format) && format[i] == '%' {
+			continue
+		}
+		for i < len(format) && strings.IndexByte("+-# 0123456789.*[", format[i]) >= 0 {
+			switch format[i] {
+			case '[':
+				return n
+			case '*':
+				used++
+			}
+			i++
+		}
+		if i < len(format) {
+			used++
+		}
+	}
+	if used > n {
+		return n
+	}
+	return used
+}
+
+// icuValue returns the value of an ICU argument: numbered arguments such
+// as {0} are positional args, named ones are params
+func (e *Error) icuValue(name string) (interface{}, bool) {
+	if index, err := strconv.Atoi(name); err == nil {
+		if index < 0 || index >= len(e.args) {
+			return nil, false
+		}
+		return e.args[index], true
+	}
+	v, ok := e.params[name]
+	return v, ok
+}
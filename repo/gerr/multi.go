@@ -0,0 +1,81 @@
+package gerr
+
+import "strings"
+
+// FieldError is an *Error optionally tagged with the field path it applies to
+type FieldError struct {
+	Field string `json:"field,omitempty"`
+	Err   *Error `json:"error"`
+}
+
+// ErrorList collects several errors, e.g. all field errors of a validation.
+// Wrap it in a defined error to process it, which makes DefaultFormatter
+// render every member:
+//
+//	list := gerr.Multi().AddField("email", errs.InvalidEmail.Args(email))
+//	return errs.ValidationFailed.Wrap(list)
+type ErrorList struct {
+	errs []FieldError
+}
+
+// Multi creates an error list holding errs
+func Multi(errs ...*Error) *ErrorList {
+	l := &ErrorList{}
+	for _, err := range errs {
+		l.Add(err)
+	}
+	return l
+}
+
+// Add appends an error; nil errors are ignored
+func (l *ErrorList) Add(err *Error) *ErrorList {
+	return l.AddField("", err)
+}
+
+// AddField appends an error tagged with a field path; nil errors are ignored
+func (l *ErrorList) AddField(field string, err *Error) *ErrorList {
+	if err != nil {
+		l.errs = append(l.errs, FieldError{Field: field, Err: err})
+	}
+	return l
+}
+
+// Len returns the number of collected errors
+func (l *ErrorList) Len() int {
+	return len(l.errs)
+}
+
+// Errors returns the collected errors
+func (l *ErrorList) Errors() []FieldError {
+	return append([]FieldError(nil), l.errs...)
+}
+
+// ErrorOrNil returns the list as an error, or nil when it is empty
+func (l *ErrorList) ErrorOrNil() error {
+	if l == nil || len(l.errs) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Error implements the error interface
+func (l *ErrorList) Error() string {
+	parts := make([]string, 0, len(l.errs))
+	for _, fe := range l.errs {
+		if fe.Field != "" {
+			parts = append(parts, fe.Field+": "+fe.Err.Error())
+		} else {
+			parts = append(parts, fe.Err.Error())
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Unwrap returns the collected errors for errors.Is and errors.As
+func (l *ErrorList) Unwrap() []error {
+	result := make([]error, 0, len(l.errs))
+	for _, fe := range l.errs {
+		result = append(result, fe.Err)
+	}
+	return result
+}
@@ -0,0 +1,124 @@
+// Package otelx records gerr errors on OpenTelemetry spans.
+package otelx
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultPrefix is the prefix of the recorded attributes
+const DefaultPrefix = "gerr"
+
+// Recorder records errors on the span of their context
+type Recorder struct {
+	prefix string
+}
+
+// NewRecorder creates a recorder using DefaultPrefix
+func NewRecorder() *Recorder {
+	return &Recorder{prefix: DefaultPrefix}
+}
+
+// SetPrefix sets the prefix of the recorded attributes,
+// e.g. "app.error" gives app.error.code
+func (r *Recorder) SetPrefix(prefix string) *Recorder {
+	r.prefix = prefix
+	return r
+}
+
+// Middleware returns a gerr.Middleware that records errors before passing
+// them on; add it with ProcessorEngine.AddMiddleware
+func (r *Recorder) Middleware() gerr.Middleware {
+	return func(ctx context.Context, err *gerr.Error, next func(context.Context, *gerr.Error) interface{}) interface{} {
+		r.Record(ctx, err)
+		return next(ctx, err)
+	}
+}
+
+// Record adds err to the span of ctx as an exception event with its code,
+// key, category, severity and public metadata. The span status is set to
+// Error for the error and critical severities only, so warnings do not mark
+// the span as failed.
+func (r *Recorder) Record(ctx context.Context, err *gerr.Error) {
+	if err == nil {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	severity := Severity(err)
+	attrs := []attribute.KeyValue{
+		attribute.String(r.key("code"), err.GetCode()),
+		attribute.String(r.key("key"), err.GetKey()),
+		attribute.String(r.key("severity"), string(severity)),
+	}
+	if def := err.GetErrWrapper(); def != nil && def.Category != "" {
+		attrs = append(attrs, attribute.String(r.key("category"), def.Category))
+	}
+	for k, v := range err.GetPublicMetadata() {
+		attrs = append(attrs, attributeValue(r.key("metadata."+k), v))
+	}
+
+	span.RecordError(err, trace.WithAttributes(attrs...))
+	if severity == gerr.SeverityError || severity == gerr.SeverityCritical {
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func (r *Recorder) key(name string) string {
+	if r.prefix == "" {
+		return name
+	}
+	return r.prefix + "." + name
+}
+
+// Severity returns the severity of err; errors without a definition or
+// severity count as gerr.SeverityError
+func Severity(err *gerr.Error) gerr.Severity {
+	if def := err.GetErrWrapper(); def != nil && def.Severity != "" {
+		return def.Severity
+	}
+	return gerr.SeverityError
+}
+
+// attributeValue converts a metadata value to an attribute, keeping the
+// types attributes support and formatting the others with fmt
+func attributeValue(key string, v interface{}) attribute.KeyValue {
+	switch val := v.(type) {
+	case string:
+		return attribute.String(key, val)
+	case bool:
+		return attribute.Bool(key, val)
+	case int:
+		return attribute.Int(key, val)
+	case int64:
+		return attribute.Int64(key, val)
+	case float64:
+		return attribute.Float64(key, val)
+	case []string:
+		return attribute.StringSlice(key, val)
+	case fmt.Stringer:
+		return attribute.String(key, val.String())
+	}
+	return attribute.String(key, fmt.Sprint(v))
+}
+
+var defaultRecorder = NewRecorder()
+
+// Middleware records errors on the active span with the default recorder
+func Middleware(ctx context.Context, err *gerr.Error, next func(context.Context, *gerr.Error) interface{}) interface{} {
+	defaultRecorder.Record(ctx, err)
+	return next(ctx, err)
+}
+
+// Record adds err to the span of ctx with the default recorder
+func Record(ctx context.Context, err *gerr.Error) {
+	defaultRecorder.Record(ctx, err)
+}
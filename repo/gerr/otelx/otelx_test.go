@@ -0,0 +1,127 @@
+package otelx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func definition(severity gerr.Severity) gerr.ErrWrapper {
+	return gerr.ErrWrapper{
+		Key:      "otelx_" + string(severity),
+		Code:     "OTELX_" + string(severity),
+		Category: "resource",
+		Severity: severity,
+		Messages: map[string]string{"en": "Order not found: %s"},
+		MetadataVisibility: map[string]gerr.Visibility{
+			"order_id": gerr.VisibilityPublic,
+			"attempt":  gerr.VisibilityPublic,
+		},
+	}
+}
+
+// process runs err through an engine using middleware inside a span and
+// returns the ended span
+func process(t *testing.T, middleware gerr.Middleware, err *gerr.Error) sdktrace.ReadOnlySpan {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	ctx, span := provider.Tracer("otelx").Start(context.Background(), "operation")
+	gerr.NewProcessorEngine().AddMiddleware(middleware).Process(ctx, err)
+	span.End()
+
+	spans := exporter.GetSpans().Snapshots()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	return spans[0]
+}
+
+func exceptionAttributes(t *testing.T, span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	t.Helper()
+	for _, event := range span.Events() {
+		if event.Name == "exception" {
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, kv := range event.Attributes {
+				attrs[kv.Key] = kv.Value
+			}
+			return attrs
+		}
+	}
+	t.Fatalf("no exception event in %v", span.Events())
+	return nil
+}
+
+func TestRecordAttributes(t *testing.T) {
+	err := gerr.NewError(definition(gerr.SeverityError)).
+		Args("o-1").
+		With("order_id", "o-1").
+		With("attempt", 2).
+		With("query", "SELECT * FROM orders")
+
+	attrs := exceptionAttributes(t, process(t, Middleware, err))
+
+	want := map[attribute.Key]attribute.Value{
+		"gerr.code":              attribute.StringValue("OTELX_error"),
+		"gerr.key":               attribute.StringValue("otelx_error"),
+		"gerr.category":          attribute.StringValue("resource"),
+		"gerr.severity":          attribute.StringValue("error"),
+		"gerr.metadata.order_id": attribute.StringValue("o-1"),
+		"gerr.metadata.attempt":  attribute.IntValue(2),
+		"exception.message":      attribute.StringValue("Order not found: o-1"),
+	}
+	for k, v := range want {
+		if got, ok := attrs[k]; !ok || got != v {
+			t.Errorf("%s = %v, want %v", k, got.Emit(), v.Emit())
+		}
+	}
+	if _, ok := attrs["gerr.metadata.query"]; ok {
+		t.Error("private metadata was recorded")
+	}
+}
+
+func TestStatusBySeverity(t *testing.T) {
+	tests := []struct {
+		severity gerr.Severity
+		want     codes.Code
+	}{
+		{gerr.SeverityWarning, codes.Unset},
+		{gerr.SeverityError, codes.Error},
+		{gerr.SeverityCritical, codes.Error},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.severity), func(t *testing.T) {
+			span := process(t, Middleware, gerr.NewError(definition(tt.severity)).Args("o-1"))
+
+			if got := span.Status().Code; got != tt.want {
+				t.Errorf("status = %v, want %v", got, tt.want)
+			}
+			exceptionAttributes(t, span)
+		})
+	}
+}
+
+func TestRecorderPrefix(t *testing.T) {
+	recorder := NewRecorder().SetPrefix("app.error")
+	span := process(t, recorder.Middleware(), gerr.NewError(definition(gerr.SeverityError)).Args("o-1"))
+
+	attrs := exceptionAttributes(t, span)
+	if got := attrs["app.error.code"]; got.AsString() != "OTELX_error" {
+		t.Errorf("app.error.code = %q, want OTELX_error", got.AsString())
+	}
+	if _, ok := attrs["gerr.code"]; ok {
+		t.Error("default prefix used despite SetPrefix")
+	}
+}
+
+func TestRecordWithoutSpan(t *testing.T) {
+	// no span in ctx: must not panic
+	Record(context.Background(), gerr.NewError(definition(gerr.SeverityError)))
+	Record(context.Background(), nil)
+}
@@ -0,0 +1,220 @@
+package gerr
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// Plural categories defined by CLDR
+const (
+	PluralZero  = "zero"
+	PluralOne   = "one"
+	PluralTwo   = "two"
+	PluralFew   = "few"
+	PluralMany  = "many"
+	PluralOther = "other"
+)
+
+// pluralCategories lists the CLDR plural categories in their canonical order
+var pluralCategories = []string{PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther}
+
+// pluralNames maps x/text plural forms to CLDR category names
+var pluralNames = map[plural.Form]string{
+	plural.Zero:  PluralZero,
+	plural.One:   PluralOne,
+	plural.Two:   PluralTwo,
+	plural.Few:   PluralFew,
+	plural.Many:  PluralMany,
+	plural.Other: PluralOther,
+}
+
+// languageAliases maps language codes commonly used in message files to
+// BCP 47 tags
+var languageAliases = map[string]string{
+	"cn": "zh",
+	"jp": "ja",
+	"kr": "ko",
+}
+
+// IsPluralCategory reports whether name is a CLDR plural category
+func IsPluralCategory(name string) bool {
+	for _, c := range pluralCategories {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PluralForms returns the plural categories used by the cardinal rules of
+// lang, in canonical order. "other" is always included.
+func PluralForms(lang string) []string {
+	tag := languageTag(lang)
+	used := map[string]bool{PluralOther: true}
+
+	// probe integers, large numbers and decimals with one and two fraction digits
+	for i := 0; i <= 200; i++ {
+		used[pluralNames[plural.Cardinal.MatchPlural(tag, i, 0, 0, 0, 0)]] = true
+	}
+	for _, i := range []int{1000, 10000, 100000, 1000000} {
+		used[pluralNames[plural.Cardinal.MatchPlural(tag, i, 0, 0, 0, 0)]] = true
+	}
+	for i := 0; i <= 20; i++ {
+		for f := 1; f <= 9; f++ {
+			used[pluralNames[plural.Cardinal.MatchPlural(tag, i, 1, 1, f, f)]] = true
+			used[pluralNames[plural.Cardinal.MatchPlural(tag, i, 2, 2, f*10+1, f*10+1)]] = true
+		}
+	}
+
+	var result []string
+	for _, c := range pluralCategories {
+		if used[c] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// PluralCategory returns the plural category of n in lang.
+// n may be any integer or float type, or a decimal string such as "1.50"
+// whose visible fraction digits are taken into account.
+func PluralCategory(lang string, n interface{}) string {
+	return matchPlural(plural.Cardinal, lang, n)
+}
+
+// OrdinalCategory returns the ordinal plural category of n in lang,
+// e.g. "one" for 1st, "two" for 2nd and "few" for 3rd in English
+func OrdinalCategory(lang string, n interface{}) string {
+	return matchPlural(plural.Ordinal, lang, n)
+}
+
+// matchPlural computes the plural operands of n and matches them with rules
+func matchPlural(rules *plural.Rules, lang string, n interface{}) string {
+	digits, ok := decimalString(n)
+	if !ok {
+		return PluralOther
+	}
+	digits = strings.TrimPrefix(digits, "-")
+
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	i := lastDigits(intPart, 7)
+	v := len(fracPart)
+	trimmed := strings.TrimRight(fracPart, "0")
+	w := len(trimmed)
+	f := lastDigits(fracPart, 7)
+	t := lastDigits(trimmed, 7)
+
+	return pluralNames[rules.MatchPlural(languageTag(lang), i, v, w, f, t)]
+}
+
+// decimalString renders a numeric value as a plain decimal string
+func decimalString(n interface{}) (string, bool) {
+	switch v := n.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), true
+	case int8:
+		return strconv.FormatInt(int64(v), 10), true
+	case int16:
+		return strconv.FormatInt(int64(v), 10), true
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float32:
+		return formatFloat(float64(v))
+	case float64:
+		return formatFloat(v)
+	case json.Number:
+		return decimalString(string(v))
+	case string:
+		if _, err := strconv.ParseFloat(v, 64); err != nil || strings.ContainsAny(v, "eEx") {
+			return "", false
+		}
+		return v, true
+	}
+	return "", false
+}
+
+func formatFloat(v float64) (string, bool) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "", false
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64), true
+}
+
+// lastDigits parses at most the last n digits of s, which keeps the
+// plural operands within the range the rules need
+func lastDigits(s string, n int) int {
+	if len(s) > n {
+		s = s[len(s)-n:]
+	}
+	if s == "" {
+		return 0
+	}
+	v, _ := strconv.Atoi(s)
+	return v
+}
+
+// languageTag parses lang as a BCP 47 tag, resolving common aliases
+func languageTag(lang string) language.Tag {
+	lang = strings.ReplaceAll(lang, "_", "-")
+	if alias, ok := languageAliases[strings.ToLower(lang)]; ok {
+		lang = alias
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
+
+// pluralValue returns the value of the argument that selects plural forms
+func (e *Error) pluralValue() (interface{}, bool) {
+	if e.errWrapper == nil || e.errWrapper.PluralArg == "" {
+		return nil, false
+	}
+	arg := e.errWrapper.PluralArg
+	if index, err := strconv.Atoi(arg); err == nil {
+		if index < 1 || index > len(e.args) {
+			return nil, false
+		}
+		return e.args[index-1], true
+	}
+	v, ok := e.params[arg]
+	return v, ok
+}
+
+// messageTemplate returns the message template for lang, selecting the plural form
+// from the plural argument when the definition has plural forms for lang
+func (e *Error) messageTemplate(lang string) (string, bool) {
+	if e.errWrapper == nil {
+		return "", false
+	}
+	if forms, ok := e.errWrapper.Plurals[lang]; ok {
+		if n, ok := e.pluralValue(); ok {
+			if msg, ok := forms[PluralCategory(lang, n)]; ok {
+				return msg, true
+			}
+		}
+		if msg, ok := forms[PluralOther]; ok {
+			return msg, true
+		}
+	}
+	msg, ok := e.errWrapper.Messages[lang]
+	return msg, ok
+}
@@ -0,0 +1,32 @@
+package gerr
+
+import "testing"
+
+func TestPluralFormWithFewerArgs(t *testing.T) {
+	def := ErrWrapper{
+		Key:       "plural_attempts",
+		Code:      "PLURAL_ATTEMPTS",
+		Messages:  map[string]string{"en": "%d failed attempts for %s"},
+		PluralArg: "1",
+		Plurals: map[string]map[string]string{
+			"en": {
+				PluralOne:   "One failed attempt",
+				PluralOther: "%d failed attempts for %s",
+			},
+		},
+	}
+	localizer := NewDefaultLocalizer()
+
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "One failed attempt"},
+		{3, "3 failed attempts for alice"},
+	}
+	for _, tt := range tests {
+		if got := localizer.LocalizeWithLanguage("en", NewError(def).Args(tt.n, "alice")); got != tt.want {
+			t.Errorf("n=%d: got %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package gerr
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// problemMembers are the standard RFC 9457 members and the extensions set
+// by the formatter; extension members taken from metadata never override them
+var problemMembers = map[string]bool{
+	"type":        true,
+	"title":       true,
+	"status":      true,
+	"detail":      true,
+	"instance":    true,
+	"code":        true,
+	"retryable":   true,
+	"retry_after": true,
+	"errors":      true,
+}
+
+// ProblemFormatter formats errors as RFC 9457 problem details
+type ProblemFormatter struct {
+	localizer Localizer
+	typeBase  string
+}
+
+// NewProblemFormatter creates a problem details formatter.
+// typeBase is joined with the error code to build the "type" URI,
+// e.g. "https://example.com/errors" gives "https://example.com/errors/USER_NOT_FOUND".
+// An empty typeBase yields "about:blank".
+func NewProblemFormatter(typeBase string) *ProblemFormatter {
+	return &ProblemFormatter{
+		localizer: NewDefaultLocalizer(),
+		typeBase:  typeBase,
+	}
+}
+
+// SetLocalizer sets the localizer used for the "detail" member
+func (f *ProblemFormatter) SetLocalizer(localizer Localizer) *ProblemFormatter {
+	f.localizer = localizer
+	return f
+}
+
+// Format formats an error as problem details for API clients: only
+// public metadata becomes extension members
+func (f *ProblemFormatter) Format(ctx context.Context, err *Error) interface{} {
+	return f.FormatWithOptions(ctx, err, FormatOptions{
+		IncludeMetadata: true,
+		Format:          FormatTypeProblem,
+		Audience:        AudiencePublic,
+	})
+}
+
+// FormatWithOptions formats an error as problem details with specific options
+func (f *ProblemFormatter) FormatWithOptions(ctx context.Context, err *Error, options FormatOptions) interface{} {
+	return f.formatProblem(ctx, err, options)
+}
+
+// formatProblem builds the problem details members
+func (f *ProblemFormatter) formatProblem(ctx context.Context, err *Error, options FormatOptions) map[string]interface{} {
+	// problem details are meant for clients
+	if options.Audience == "" {
+		options.Audience = AudiencePublic
+	}
+	status := err.HTTPStatus()
+
+	result := map[string]interface{}{
+		"type":   f.problemType(err),
+		"title":  problemTitle(err, status),
+		"status": status,
+	}
+
+	result["detail"] = localizeFor(ctx, f.localizer, err, options)
+
+	if options.Instance != "" {
+		result["instance"] = options.Instance
+	}
+
+	if err.code != "" {
+		result["code"] = err.code
+	}
+
+	if err.errWrapper != nil && err.errWrapper.Retryable {
+		result["retryable"] = true
+		if err.errWrapper.RetryAfter > 0 {
+			result["retry_after"] = retryAfterSeconds(err.errWrapper.RetryAfter)
+		}
+	}
+
+	if list, ok := err.cause.(*ErrorList); ok {
+		errs := make([]map[string]interface{}, 0, list.Len())
+		for _, fe := range list.errs {
+			item := map[string]interface{}{
+				"code":   fe.Err.code,
+				"detail": f.formatProblem(ctx, fe.Err, FormatOptions{Language: options.Language})["detail"],
+			}
+			if fe.Field != "" {
+				item["field"] = fe.Field
+			}
+			errs = append(errs, item)
+		}
+		result["errors"] = errs
+	}
+
+	if options.IncludeMetadata {
+		for k, v := range err.metadataFor(options.Audience) {
+			if !problemMembers[k] {
+				result[k] = v
+			}
+		}
+	}
+
+	return result
+}
+
+// problemType builds the "type" URI from the base and the error code
+func (f *ProblemFormatter) problemType(err *Error) string {
+	if f.typeBase == "" || err.code == "" {
+		return "about:blank"
+	}
+	return strings.TrimSuffix(f.typeBase, "/") + "/" + err.code
+}
+
+// problemTitle returns the definition description, or the status text
+func problemTitle(err *Error, status int) string {
+	if err.errWrapper != nil && err.errWrapper.Description != "" {
+		return err.errWrapper.Description
+	}
+	return http.StatusText(status)
+}
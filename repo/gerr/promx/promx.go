@@ -0,0 +1,94 @@
+// Package promx exports Prometheus metrics about gerr errors.
+package promx
+
+import (
+	"context"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnknownLabel is the code and category label of errors whose key is not
+// registered, which keeps the number of series bounded
+const UnknownLabel = "unknown"
+
+// Metrics counts processed errors by code, category and severity. It is a
+// prometheus.Collector; register it with a prometheus.Registerer.
+type Metrics struct {
+	errors   *prometheus.CounterVec
+	registry gerr.Registry
+}
+
+// NewMetrics creates the gerr_errors_total counter, prefixed with namespace
+// when it is not empty, backed by the global registry
+func NewMetrics(namespace string) *Metrics {
+	return &Metrics{
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "gerr_errors_total",
+			Help:      "Number of processed errors by code, category and severity.",
+		}, []string{"code", "category", "severity"}),
+		registry: gerr.GlobalRegistry(),
+	}
+}
+
+// SetRegistry sets the registry whose definitions bound the label values
+func (m *Metrics) SetRegistry(registry gerr.Registry) *Metrics {
+	m.registry = registry
+	return m
+}
+
+// Preregister creates a zero-valued series for every registered definition,
+// so alerts on codes that have not fired yet see 0 instead of no data.
+// Call it after the definitions are registered.
+func (m *Metrics) Preregister() *Metrics {
+	for _, def := range m.registry.List() {
+		m.errors.WithLabelValues(def.Code, def.Category, string(def.Severity))
+	}
+	return m
+}
+
+// Middleware returns a gerr.Middleware that counts errors before passing
+// them on; add it with ProcessorEngine.AddMiddleware
+func (m *Metrics) Middleware() gerr.Middleware {
+	return func(ctx context.Context, err *gerr.Error, next func(context.Context, *gerr.Error) interface{}) interface{} {
+		m.Observe(err)
+		return next(ctx, err)
+	}
+}
+
+// Observe counts err. The labels come from the registered definition of its
+// key; unregistered errors are counted under UnknownLabel.
+func (m *Metrics) Observe(err *gerr.Error) {
+	if err == nil {
+		return
+	}
+	m.errors.WithLabelValues(m.labels(err)...).Inc()
+}
+
+// labels returns the code, category and severity labels of err
+func (m *Metrics) labels(err *gerr.Error) []string {
+	if m.registry != nil {
+		if def, ok := m.registry.Get(err.GetKey()); ok {
+			return []string{def.Code, def.Category, string(def.Severity)}
+		}
+	}
+	severity := gerr.SeverityError
+	if def := err.GetErrWrapper(); def != nil {
+		switch def.Severity {
+		case gerr.SeverityWarning, gerr.SeverityCritical:
+			severity = def.Severity
+		}
+	}
+	return []string{UnknownLabel, UnknownLabel, string(severity)}
+}
+
+// Describe implements prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.errors.Collect(ch)
+}
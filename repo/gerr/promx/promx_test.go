@@ -0,0 +1,98 @@
+package promx
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var (
+	notFound = gerr.ErrWrapper{
+		Key:      "promx_not_found",
+		Code:     "PROMX_NOT_FOUND",
+		Category: "resource",
+		Severity: gerr.SeverityError,
+		Messages: map[string]string{"en": "Not found"},
+	}
+	busy = gerr.ErrWrapper{
+		Key:      "promx_busy",
+		Code:     "PROMX_BUSY",
+		Category: "system",
+		Severity: gerr.SeverityWarning,
+		Messages: map[string]string{"en": "Busy"},
+	}
+)
+
+func newMetrics(t *testing.T) *Metrics {
+	t.Helper()
+	registry := gerr.NewCacheRegistry()
+	for _, def := range []gerr.ErrWrapper{notFound, busy} {
+		if err := registry.Register(def); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewMetrics("test").SetRegistry(registry)
+}
+
+const header = `
+# HELP test_gerr_errors_total Number of processed errors by code, category and severity.
+# TYPE test_gerr_errors_total counter
+`
+
+func TestObserve(t *testing.T) {
+	m := newMetrics(t)
+	m.Observe(gerr.NewError(notFound))
+	m.Observe(gerr.NewError(notFound).Args("x"))
+	m.Observe(gerr.NewError(busy))
+	m.Observe(nil)
+
+	want := header + `test_gerr_errors_total{category="resource",code="PROMX_NOT_FOUND",severity="error"} 2
+test_gerr_errors_total{category="system",code="PROMX_BUSY",severity="warning"} 1
+`
+	if err := testutil.CollectAndCompare(m, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	m := newMetrics(t)
+	engine := gerr.NewProcessorEngine().AddMiddleware(m.Middleware())
+	engine.Process(context.Background(), gerr.NewError(busy))
+
+	if got := testutil.ToFloat64(m.errors.WithLabelValues("PROMX_BUSY", "system", "warning")); got != 1 {
+		t.Errorf("counter = %v, want 1", got)
+	}
+}
+
+func TestUnregisteredKeyIsUnknown(t *testing.T) {
+	m := newMetrics(t)
+	m.Observe(gerr.New("not_registered"))
+	m.Observe(gerr.NewError(gerr.ErrWrapper{Key: "other", Code: "OTHER", Severity: gerr.SeverityCritical}))
+	m.Observe(gerr.NewError(gerr.ErrWrapper{Key: "custom", Code: "CUSTOM", Severity: "custom"}))
+
+	want := header + `test_gerr_errors_total{category="unknown",code="unknown",severity="critical"} 1
+test_gerr_errors_total{category="unknown",code="unknown",severity="error"} 2
+`
+	if err := testutil.CollectAndCompare(m, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPreregister(t *testing.T) {
+	m := newMetrics(t).Preregister()
+
+	want := header + `test_gerr_errors_total{category="resource",code="PROMX_NOT_FOUND",severity="error"} 0
+test_gerr_errors_total{category="system",code="PROMX_BUSY",severity="warning"} 0
+`
+	if err := testutil.CollectAndCompare(m, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+
+	m.Observe(gerr.NewError(busy))
+	if got := testutil.CollectAndCount(m); got != 2 {
+		t.Errorf("series = %d, want 2", got)
+	}
+}
@@ -0,0 +1,263 @@
+package gerr
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedactMask replaces redacted values
+const DefaultRedactMask = "[REDACTED]"
+
+// Detector finds secrets inside strings
+type Detector struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Valid   func(match string) bool // optional check of a match, e.g. a checksum
+}
+
+// Built-in detectors
+var (
+	DetectBearerToken = Detector{
+		Name:    "bearer_token",
+		Pattern: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`),
+	}
+	DetectJWT = Detector{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	}
+	DetectEmail = Detector{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	}
+	DetectCreditCard = Detector{
+		Name:    "credit_card",
+		Pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Valid:   luhnValid,
+	}
+)
+
+// DefaultRedactKeys are the metadata and param keys whose values are always
+// redacted. Keys match case-insensitively, ignoring '-' and '_', when they
+// contain one of these names.
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secret", "token", "apikey", "authorization",
+	"cookie", "creditcard", "cardnumber", "cvv", "ssn",
+}
+
+// Redactor scrubs secrets from the args, params, metadata and causes of
+// errors before they are formatted
+type Redactor struct {
+	keys      []string
+	detectors []Detector
+	mask      string
+}
+
+// NewRedactor creates a redactor with the default keys and the built-in
+// detectors for bearer tokens, JWTs, emails and credit card numbers
+func NewRedactor() *Redactor {
+	r := &Redactor{mask: DefaultRedactMask}
+	r.AddKeys(DefaultRedactKeys...)
+	r.detectors = []Detector{DetectBearerToken, DetectJWT, DetectEmail, DetectCreditCard}
+	return r
+}
+
+// AddKeys adds key names whose values are redacted entirely
+func (r *Redactor) AddKeys(keys ...string) *Redactor {
+	for _, k := range keys {
+		r.keys = append(r.keys, normalizeRedactKey(k))
+	}
+	return r
+}
+
+// AddPattern adds a regular expression whose matches are redacted
+func (r *Redactor) AddPattern(name string, pattern *regexp.Regexp) *Redactor {
+	return r.AddDetector(Detector{Name: name, Pattern: pattern})
+}
+
+// AddDetector adds a detector whose matches are redacted
+func (r *Redactor) AddDetector(detector Detector) *Redactor {
+	r.detectors = append(r.detectors, detector)
+	return r
+}
+
+// SetDetectors replaces the detectors, e.g. to drop the email detector
+func (r *Redactor) SetDetectors(detectors ...Detector) *Redactor {
+	r.detectors = append([]Detector(nil), detectors...)
+	return r
+}
+
+// SetMask sets the text that replaces redacted values
+func (r *Redactor) SetMask(mask string) *Redactor {
+	r.mask = mask
+	return r
+}
+
+// Middleware returns a Middleware that redacts errors before passing them on
+func (r *Redactor) Middleware() Middleware {
+	return func(ctx context.Context, err *Error, next func(context.Context, *Error) interface{}) interface{} {
+		return next(ctx, r.Redact(err))
+	}
+}
+
+// Redact returns a copy of err whose args, params, metadata and causes are
+// scrubbed. Non-gerr causes are replaced by an error with the scrubbed
+// message that still unwraps to the original for errors.Is and errors.As.
+func (r *Redactor) Redact(err *Error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	result := err.clone()
+	for i, arg := range result.args {
+		result.args[i] = r.value(arg)
+	}
+	for k, v := range result.params {
+		result.params[k] = r.keyed(k, v)
+	}
+	for k, v := range result.metadata {
+		result.metadata[k] = r.keyed(k, v)
+	}
+	result.message = r.String(result.message)
+
+	switch cause := err.cause.(type) {
+	case nil:
+	case *Error:
+		result.cause = r.Redact(cause)
+	case *ErrorList:
+		list := &ErrorList{}
+		for _, fe := range cause.errs {
+			list.AddField(fe.Field, r.Redact(fe.Err))
+		}
+		result.cause = list
+	default:
+		if msg := r.String(cause.Error()); msg != cause.Error() {
+			result.cause = &redactedError{msg: msg, err: cause}
+		}
+	}
+	return result
+}
+
+// String redacts the detector matches in s
+func (r *Redactor) String(s string) string {
+	for _, d := range r.detectors {
+		s = d.Pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if d.Valid != nil && !d.Valid(match) {
+				return match
+			}
+			return r.mask
+		})
+	}
+	return s
+}
+
+// keyed redacts a value stored under key
+func (r *Redactor) keyed(key string, v interface{}) interface{} {
+	if r.sensitiveKey(key) {
+		return r.mask
+	}
+	return r.value(v)
+}
+
+// value redacts strings, errors and stringers, and the members of maps and
+// slices; other values are returned unchanged
+func (r *Redactor) value(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return r.String(val)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			result[k] = r.keyed(k, item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = r.value(item)
+		}
+		return result
+	case []string:
+		result := make([]string, len(val))
+		for i, item := range val {
+			result[i] = r.String(item)
+		}
+		return result
+	case *Error:
+		return r.Redact(val)
+	case error:
+		if msg := r.String(val.Error()); msg != val.Error() {
+			return msg
+		}
+	case fmt.Stringer:
+		if msg := r.String(val.String()); msg != val.String() {
+			return msg
+		}
+	}
+	return v
+}
+
+// sensitiveKey reports whether key names a secret
+func (r *Redactor) sensitiveKey(key string) bool {
+	normalized := normalizeRedactKey(key)
+	for _, k := range r.keys {
+		if k != "" && strings.Contains(normalized, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeRedactKey(key string) string {
+	key = strings.ToLower(key)
+	key = strings.ReplaceAll(key, "_", "")
+	return strings.ReplaceAll(key, "-", "")
+}
+
+// redactedError is a cause whose message was scrubbed
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// luhnValid checks the Luhn checksum of a card number, ignoring separators
+func luhnValid(s string) bool {
+	sum := 0
+	double := false
+	digits := 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+var defaultRedactor = NewRedactor()
+
+// RedactMiddleware scrubs secrets with the default redactor rules; add it
+// with ProcessorEngine.AddMiddleware. Use NewRedactor().Middleware() for
+// custom rules.
+func RedactMiddleware(ctx context.Context, err *Error, next func(context.Context, *Error) interface{}) interface{} {
+	return next(ctx, defaultRedactor.Redact(err))
+}
@@ -7,8 +7,9 @@ import (
 
 // CacheRegistry is an in-memory implementation of Registry
 type CacheRegistry struct {
-	mu      sync.RWMutex
-	wrapper map[string]ErrWrapper
+	mu              sync.RWMutex
+	wrapper         map[string]ErrWrapper
+	defaultLanguage string
 }
 
 // NewCacheRegistry creates a new memory registry
@@ -51,6 +52,23 @@ func (r *CacheRegistry) Register(def ErrWrapper) error {
 	return nil
 }
 
+// SetDefaultLanguage sets the language used when a requested language and
+// its fallbacks have no message
+func (r *CacheRegistry) SetDefaultLanguage(lang string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.defaultLanguage = lang
+}
+
+// DefaultLanguage returns the registry default language, or "" when unset
+func (r *CacheRegistry) DefaultLanguage() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.defaultLanguage
+}
+
 // Get retrieves an error definition by key
 func (r *CacheRegistry) Get(key string) (ErrWrapper, bool) {
 	r.mu.RLock()
@@ -95,7 +113,19 @@ func (r *CacheRegistry) Clear() {
 // Global registry instance
 var globalRegistry Registry = NewCacheRegistry()
 
+// GlobalRegistry returns the registry used by Register and the global engine
+func GlobalRegistry() Registry {
+	return globalRegistry
+}
+
 // Register registers an error wrapper globally
 func Register(def ErrWrapper) error {
 	return globalRegistry.Register(def)
 }
+
+// SetDefaultLanguage sets the default language of the global registry
+func SetDefaultLanguage(lang string) {
+	if r, ok := globalRegistry.(interface{ SetDefaultLanguage(string) }); ok {
+		r.SetDefaultLanguage(lang)
+	}
+}
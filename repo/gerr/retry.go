@@ -0,0 +1,58 @@
+package gerr
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IsRetryable reports whether the first defined *Error in err's chain is
+// marked retryable
+func IsRetryable(err error) bool {
+	if e := findDefined(err); e != nil {
+		return e.errWrapper.Retryable
+	}
+	return false
+}
+
+// RetryAfter returns the retry delay of the first defined *Error in err's
+// chain. The second result is false when the error is not retryable or
+// carries no delay.
+func RetryAfter(err error) (time.Duration, bool) {
+	e := findDefined(err)
+	if e == nil || !e.errWrapper.Retryable || e.errWrapper.RetryAfter <= 0 {
+		return 0, false
+	}
+	return e.errWrapper.RetryAfter, true
+}
+
+// SetRetryAfterHeader sets the Retry-After header, in whole seconds,
+// when err carries a retry delay
+func SetRetryAfterHeader(header http.Header, err error) {
+	if d, ok := RetryAfter(err); ok {
+		header.Set("Retry-After", strconv.Itoa(retryAfterSeconds(d)))
+	}
+}
+
+// retryAfterSeconds rounds d up to whole seconds
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// findDefined walks err's chain and returns the first *Error that has a
+// definition
+func findDefined(err error) *Error {
+	for err != nil {
+		var e *Error
+		if !errors.As(err, &e) {
+			return nil
+		}
+		if e.errWrapper != nil {
+			return e
+		}
+		err = e.cause
+	}
+	return nil
+}
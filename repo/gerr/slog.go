@@ -0,0 +1,55 @@
+package gerr
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// LogValue implements slog.LogValuer so that logging an *Error keeps its
+// code, category, severity, metadata, cause chain and captured stack
+func (e *Error) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("message", e.Error()),
+		slog.String("key", e.key),
+	}
+	if e.code != "" {
+		attrs = append(attrs, slog.String("code", e.code))
+	}
+	if msg := e.GetInternalMessage(); msg != "" {
+		attrs = append(attrs, slog.String("internal_message", msg))
+	}
+	if e.errWrapper != nil {
+		attrs = append(attrs,
+			slog.String("category", e.errWrapper.Category),
+			slog.String("severity", string(e.errWrapper.Severity)),
+		)
+	}
+
+	if len(e.metadata) > 0 {
+		keys := make([]string, 0, len(e.metadata))
+		for k := range e.metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		metadata := make([]any, 0, len(keys))
+		for _, k := range keys {
+			metadata = append(metadata, slog.Any(k, e.metadata[k]))
+		}
+		attrs = append(attrs, slog.Group("metadata", metadata...))
+	}
+
+	if e.cause != nil {
+		if causeErr, ok := e.cause.(*Error); ok {
+			attrs = append(attrs, slog.Any("cause", causeErr))
+		} else {
+			attrs = append(attrs, slog.String("cause", e.cause.Error()))
+		}
+	}
+
+	if len(e.stack) > 0 {
+		attrs = append(attrs, slog.Any("stack", formatFrames(e.StackTrace())))
+	}
+
+	return slog.GroupValue(attrs...)
+}
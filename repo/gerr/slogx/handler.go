@@ -0,0 +1,154 @@
+// Package slogx integrates gerr errors with log/slog.
+package slogx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+// LevelCritical is the slog level used for gerr.SeverityCritical
+const LevelCritical = slog.LevelError + 4
+
+// Options configures a Handler
+type Options struct {
+	// Prefix of the promoted attributes, "error" by default,
+	// e.g. error_code, error_category
+	Prefix string
+
+	// KeepLevel keeps the record level instead of the one derived from
+	// the error severity
+	KeepLevel bool
+}
+
+// Handler wraps a slog.Handler and promotes the fields of the first
+// *gerr.Error found in a record's attributes to top-level attributes
+type Handler struct {
+	next slog.Handler
+	opts Options
+}
+
+// NewHandler creates a handler that forwards to next
+func NewHandler(next slog.Handler, opts *Options) *Handler {
+	h := &Handler{next: next}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Prefix == "" {
+		h.opts.Prefix = "error"
+	}
+	return h
+}
+
+// Level maps an error severity to a slog level
+func Level(severity gerr.Severity) slog.Level {
+	switch severity {
+	case gerr.SeverityWarning:
+		return slog.LevelWarn
+	case gerr.SeverityCritical:
+		return LevelCritical
+	default:
+		return slog.LevelError
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+// Unless KeepLevel is set every level is accepted, because the final level
+// is only known once the error in the record has been inspected.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.opts.KeepLevel {
+		return h.next.Enabled(ctx, level)
+	}
+	return true
+}
+
+// Handle promotes gerr fields and forwards the record to the wrapped handler
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	var gErr *gerr.Error
+	r.Attrs(func(a slog.Attr) bool {
+		gErr = findError(a.Value)
+		return gErr == nil
+	})
+
+	if gErr == nil {
+		if !h.next.Enabled(ctx, r.Level) {
+			return nil
+		}
+		return h.next.Handle(ctx, r)
+	}
+
+	level := r.Level
+	if !h.opts.KeepLevel {
+		if def := gErr.GetErrWrapper(); def != nil {
+			level = Level(def.Severity)
+		}
+	}
+	if !h.next.Enabled(ctx, level) {
+		return nil
+	}
+
+	record := slog.NewRecord(r.Time, level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		record.AddAttrs(a)
+		return true
+	})
+	record.AddAttrs(h.promote(gErr)...)
+
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler whose wrapped handler has the given attributes
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs), opts: h.opts}
+}
+
+// WithGroup returns a handler whose wrapped handler has the given group
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), opts: h.opts}
+}
+
+// promote builds the top-level attributes for err
+func (h *Handler) promote(err *gerr.Error) []slog.Attr {
+	prefix := h.opts.Prefix + "_"
+	attrs := []slog.Attr{
+		slog.String(prefix+"key", err.GetKey()),
+		slog.String(prefix+"code", err.GetCode()),
+	}
+	if def := err.GetErrWrapper(); def != nil {
+		attrs = append(attrs,
+			slog.String(prefix+"category", def.Category),
+			slog.String(prefix+"severity", string(def.Severity)),
+		)
+	}
+	return attrs
+}
+
+// findError returns the *gerr.Error held by v, preferring the first one
+// in the chain that has a definition
+func findError(v slog.Value) *gerr.Error {
+	if v.Kind() != slog.KindAny && v.Kind() != slog.KindLogValuer {
+		return nil
+	}
+	err, ok := v.Any().(error)
+	if !ok {
+		return nil
+	}
+
+	var first *gerr.Error
+	for err != nil {
+		var gErr *gerr.Error
+		if !errors.As(err, &gErr) {
+			break
+		}
+		if gErr.GetErrWrapper() != nil {
+			return gErr
+		}
+		if first == nil {
+			first = gErr
+		}
+		err = gErr.GetCause()
+	}
+	return first
+}
@@ -0,0 +1,127 @@
+package gerr
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// StackMode controls how much of the call stack is captured when an Error is created
+type StackMode int32
+
+// StackMode constants
+const (
+	StackModeOff    StackMode = iota // no stack is captured
+	StackModeCaller                  // only the calling frame is captured
+	StackModeFull                    // the full call stack is captured
+)
+
+// maxStackDepth limits the number of frames captured in StackModeFull
+const maxStackDepth = 32
+
+var stackMode atomic.Int32
+
+// SetStackMode sets the stack capture mode used by New, Wrap, NewError and
+// the first method called on a template
+func SetStackMode(mode StackMode) {
+	stackMode.Store(int32(mode))
+}
+
+// GetStackMode returns the current stack capture mode
+func GetStackMode() StackMode {
+	return StackMode(stackMode.Load())
+}
+
+// callers captures the stack of the function calling the gerr constructor.
+// It must be called directly from that constructor, or through skip
+// unexported helpers.
+func callers(skip int) []uintptr {
+	depth := 0
+	switch GetStackMode() {
+	case StackModeCaller:
+		depth = 1
+	case StackModeFull:
+		depth = maxStackDepth
+	default:
+		return nil
+	}
+
+	pcs := make([]uintptr, depth)
+	// skip runtime.Callers, callers, the helpers and the gerr constructor
+	n := runtime.Callers(3+skip, pcs)
+	return pcs[:n]
+}
+
+// StackTrace returns the frames captured when the error was created
+func (e *Error) StackTrace() []runtime.Frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+
+	var result []runtime.Frame
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		result = append(result, frame)
+		if !more {
+			break
+		}
+	}
+	return result
+}
+
+// Format implements fmt.Formatter.
+// %s and %v print the error message, %q a quoted message and %+v
+// the whole cause chain together with the captured stack frames.
+// Other verbs are reported as bad verbs like fmt does.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, e.verbose())
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		// report unsupported verbs the way fmt does, e.g. %!d(*gerr.Error=msg)
+		fmt.Fprintf(s, "%%!%c(%T=%s)", verb, e, e.Error())
+	}
+}
+
+// verbose renders the error, its stack and its causes
+func (e *Error) verbose() string {
+	var b strings.Builder
+	b.WriteString(e.Error())
+	writeFrames(&b, e.StackTrace())
+
+	if e.cause != nil {
+		b.WriteString("\nCaused by: ")
+		if causeErr, ok := e.cause.(*Error); ok {
+			b.WriteString(causeErr.verbose())
+		} else {
+			fmt.Fprintf(&b, "%+v", e.cause)
+		}
+	}
+	return b.String()
+}
+
+func writeFrames(b *strings.Builder, frames []runtime.Frame) {
+	for _, frame := range frames {
+		fmt.Fprintf(b, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+	}
+}
+
+// formatFrames renders frames as "function file:line" strings
+func formatFrames(frames []runtime.Frame) []string {
+	result := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		result = append(result, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kalifun/glitch/repo/generator"
+	"github.com/kalifun/glitch/repo/gerr"
+)
+
+// formSeparator joins an error code and a plural form in entry contexts,
+// e.g. AUTH_LOGIN_ATTEMPTS_EXCEEDED|one
+const formSeparator = "|"
+
+// Entry is one translatable message
+type Entry struct {
+	Key         string
+	Code        string
+	Form        string // plural form, empty for plain messages
+	Description string
+	Source      string // message in the source language
+	Target      string // translation, empty when missing
+}
+
+// Context identifies the entry: its code, plus the plural form if any
+func (e Entry) Context() string {
+	if e.Form == "" {
+		return e.Code
+	}
+	return e.Code + formSeparator + e.Form
+}
+
+// parseContext splits an entry context into code and plural form
+func parseContext(context string) (code, form string) {
+	code, form, _ = strings.Cut(context, formSeparator)
+	return code, form
+}
+
+// Catalog holds the translations of one language
+type Catalog struct {
+	SourceLanguage string
+	Language       string
+	Entries        []Entry
+}
+
+// BuildCatalog collects the messages of every definition for lang, with
+// their source-language text. Plural definitions get one entry per plural
+// form required by lang.
+func BuildCatalog(descs []generator.ErrorDesc, sourceLang, lang string) Catalog {
+	c := Catalog{SourceLanguage: sourceLang, Language: lang}
+	for _, desc := range descs {
+		for _, item := range desc.Error {
+			entry := Entry{
+				Key:         item.Key,
+				Code:        item.Code,
+				Description: item.Description,
+			}
+
+			if !isPlural(item) {
+				entry.Source = item.Message[sourceLang]
+				entry.Target = item.Message[lang]
+				c.Entries = append(c.Entries, entry)
+				continue
+			}
+
+			for _, form := range gerr.PluralForms(lang) {
+				formEntry := entry
+				formEntry.Form = form
+				formEntry.Source = pluralMessage(item, sourceLang, form)
+				if forms, ok := item.Plurals[lang]; ok {
+					formEntry.Target = forms[form]
+				} else if form == gerr.PluralOther {
+					formEntry.Target = item.Message[lang]
+				}
+				c.Entries = append(c.Entries, formEntry)
+			}
+		}
+	}
+	return c
+}
+
+// isPlural reports whether any language of item has plural forms
+func isPlural(item generator.ErrorItem) bool {
+	return len(item.Plurals) > 0
+}
+
+// pluralMessage returns the form of lang, falling back to its "other" form
+func pluralMessage(item generator.ErrorItem, lang, form string) string {
+	if forms, ok := item.Plurals[lang]; ok {
+		if msg, ok := forms[form]; ok {
+			return msg
+		}
+		return forms[gerr.PluralOther]
+	}
+	return item.Message[lang]
+}
+
+// validate checks that the catalog names its language
+func (c Catalog) validate(name string) error {
+	if c.Language == "" {
+		return fmt.Errorf("%s: target language is not set", name)
+	}
+	return nil
+}
+
+// ReadCatalogFile reads a PO or XLIFF file, picking the format from the
+// file extension (.po, .xliff or .xlf)
+func ReadCatalogFile(path string) (Catalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Catalog{}, err
+	}
+	defer f.Close()
+
+	var c Catalog
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".po":
+		c, err = ReadPO(f)
+	case ".xliff", ".xlf":
+		c, err = ReadXLIFF(f)
+	default:
+		return c, fmt.Errorf("%s: unsupported translation file format", path)
+	}
+	if err != nil {
+		return c, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, c.validate(path)
+}
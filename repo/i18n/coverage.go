@@ -0,0 +1,68 @@
+// Package i18n reports on and exchanges the translations of error definitions.
+package i18n
+
+import (
+	"sort"
+
+	"github.com/kalifun/glitch/repo/generator"
+)
+
+// LanguageCoverage is the translation coverage of one language
+type LanguageCoverage struct {
+	Language   string   `json:"language"`
+	Translated int      `json:"translated"`
+	Total      int      `json:"total"`
+	Missing    []string `json:"missing,omitempty"` // keys without a message
+}
+
+// Percent returns the share of translated definitions, from 0 to 100
+func (c LanguageCoverage) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Translated) * 100 / float64(c.Total)
+}
+
+// Coverage computes the translation coverage of each language.
+// When languages is empty every language used by any definition is reported.
+func Coverage(descs []generator.ErrorDesc, languages []string) []LanguageCoverage {
+	if len(languages) == 0 {
+		languages = Languages(descs)
+	}
+
+	result := make([]LanguageCoverage, 0, len(languages))
+	for _, lang := range languages {
+		c := LanguageCoverage{Language: lang}
+		for _, desc := range descs {
+			for _, item := range desc.Error {
+				c.Total++
+				if item.Message[lang] != "" {
+					c.Translated++
+				} else {
+					c.Missing = append(c.Missing, item.Key)
+				}
+			}
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// Languages returns every language used by the definitions, sorted
+func Languages(descs []generator.ErrorDesc) []string {
+	seen := make(map[string]bool)
+	for _, desc := range descs {
+		for _, item := range desc.Error {
+			for lang := range item.Message {
+				seen[lang] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(seen))
+	for lang := range seen {
+		result = append(result, lang)
+	}
+	sort.Strings(result)
+	return result
+}
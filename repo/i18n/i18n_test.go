@@ -0,0 +1,246 @@
+package i18n
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// catalog has plain and plural entries, an empty target and text that
+// needs escaping in both formats
+var catalog = Catalog{
+	SourceLanguage: "en",
+	Language:       "ru",
+	Entries: []Entry{
+		{Key: "order_not_found", Code: "ORDER_NOT_FOUND", Description: "Order not found\nshown to customers",
+			Source: `Order "%s" not found`, Target: `Заказ «%s» не найден`},
+		{Key: "too_many_items", Code: "ORDER_TOO_MANY_ITEMS", Form: "one",
+			Source: "%d item exceeds the limit", Target: "%d товар превышает лимит"},
+		{Key: "too_many_items", Code: "ORDER_TOO_MANY_ITEMS", Form: "few",
+			Source: "%d items exceed the limit", Target: "%d товара превышают лимит"},
+		{Key: "markup", Code: "ORDER_MARKUP", Source: "a < b & c > d\ttab\\", Target: ""},
+	},
+}
+
+func TestPORoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WritePO(&buf, catalog); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadPO(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, catalog) {
+		t.Errorf("round trip = %+v, want %+v", got, catalog)
+	}
+}
+
+func TestXLIFFRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLIFF(&buf, catalog); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `state="needs-translation"`) {
+		t.Error("empty target not marked needs-translation")
+	}
+	got, err := ReadXLIFF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, catalog) {
+		t.Errorf("round trip = %+v, want %+v", got, catalog)
+	}
+}
+
+func TestReadPOFromTools(t *testing.T) {
+	po := `# translator comment
+msgid ""
+msgstr ""
+"Language: cn\n"
+"X-Source-Language: en\n"
+
+#. Order not found
+#: order_not_found
+msgctxt "ORDER_NOT_FOUND"
+msgid "Order not found: "
+"%s"
+msgstr "订单"
+"未找到: %s"
+
+#, fuzzy
+#: too_many_items
+msgctxt "ORDER_TOO_MANY_ITEMS|other"
+msgid "%d items"
+msgstr "%d 件商品"
+`
+	got, err := ReadPO(strings.NewReader(po))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Catalog{
+		SourceLanguage: "en",
+		Language:       "cn",
+		Entries: []Entry{{Key: "order_not_found", Code: "ORDER_NOT_FOUND", Description: "Order not found",
+			Source: "Order not found: %s", Target: "订单未找到: %s"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadPO = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadPOErrors(t *testing.T) {
+	tests := map[string]string{
+		`"orphan string"`:              "string without keyword",
+		"msgid \"a\"\nmsgplural \"b\"": "unsupported syntax",
+		`msgid "unterminated`:          "po line 1",
+	}
+	for po, want := range tests {
+		if _, err := ReadPO(strings.NewReader(po)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ReadPO(%q) error = %v, want %q", po, err, want)
+		}
+	}
+}
+
+func TestReadCatalogFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"ru.po", "ru.xlf"} {
+		var buf bytes.Buffer
+		write := WritePO
+		if filepath.Ext(name) == ".xlf" {
+			write = WriteXLIFF
+		}
+		if err := write(&buf, catalog); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadCatalogFile(path)
+		if err != nil || !reflect.DeepEqual(got, catalog) {
+			t.Errorf("ReadCatalogFile(%s) = %+v, %v", name, got, err)
+		}
+	}
+
+	if _, err := ReadCatalogFile(filepath.Join(dir, "ru.txt")); err == nil {
+		t.Error("unsupported extension accepted")
+	}
+	noLanguage := filepath.Join(dir, "none.po")
+	if err := os.WriteFile(noLanguage, []byte("msgid \"\"\nmsgstr \"\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadCatalogFile(noLanguage); err == nil || !strings.Contains(err.Error(), "target language") {
+		t.Errorf("missing language error = %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	file := copyFile(t, "testdata/merge_input.yaml")
+	catalogs := []Catalog{
+		{Language: "cn", Entries: []Entry{
+			{Code: "ORDER_NOT_FOUND", Target: "订单未找到: %s"},
+			// same text as the plain cn message: no change
+			{Code: "ORDER_TOO_MANY_ITEMS", Form: "other", Target: "%d 件商品超出限制"},
+			{Code: "ORDER_UNTOUCHED"},
+			{Code: "UNKNOWN_CODE", Target: "ignored"},
+		}},
+		{Language: "fr", Entries: []Entry{
+			{Code: "ORDER_NOT_FOUND", Target: "Commande introuvable : %s"},
+		}},
+		{Language: "ru", Entries: []Entry{
+			{Code: "ORDER_TOO_MANY_ITEMS", Form: "one", Target: "%d товар превышает лимит"},
+			{Code: "ORDER_TOO_MANY_ITEMS", Form: "other", Target: "%d товара превышают лимит"},
+		}},
+	}
+
+	results, err := Merge([]string{file}, catalogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Updated != 4 {
+		t.Errorf("results = %+v, want 4 updates", results)
+	}
+	compareGolden(t, file, "testdata/merge_want.yaml")
+
+	// merging again changes nothing and leaves the file alone
+	before, _ := os.ReadFile(file)
+	results, err = Merge([]string{file}, catalogs)
+	if err != nil || results[0].Updated != 0 {
+		t.Errorf("second merge = %+v, %v, want no update", results, err)
+	}
+	if after, _ := os.ReadFile(file); !bytes.Equal(before, after) {
+		t.Error("unchanged file was rewritten")
+	}
+}
+
+func TestMergePluralIntoPlainMessage(t *testing.T) {
+	file := copyFile(t, "testdata/merge_input.yaml")
+	_, err := Merge([]string{file}, []Catalog{{Language: "cn", Entries: []Entry{
+		{Code: "ORDER_NOT_FOUND", Form: "one", Target: "一个订单未找到: %s"},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(file)
+	want := "      cn:\n        other: \"旧的翻译: %s\"\n        one: \"一个订单未找到: %s\"\n"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("plain message not turned into plural forms:\n%s", data)
+	}
+}
+
+func TestMergeKeepsCompactFiles(t *testing.T) {
+	input := "error:\n  - key: a\n    code: A\n    message:\n      en: \"A\"\n  - key: b\n    code: B\n    message:\n      en: \"B\"\n"
+	file := filepath.Join(t.TempDir(), "errors.yaml")
+	if err := os.WriteFile(file, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Merge([]string{file}, []Catalog{{Language: "cn", Entries: []Entry{{Code: "B", Target: "乙"}}}}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(file)
+	want := "error:\n  - key: a\n    code: A\n    message:\n      en: \"A\"\n  - key: b\n    code: B\n    message:\n      en: \"B\"\n      cn: \"乙\"\n"
+	if string(data) != want {
+		t.Errorf("merged file =\n%s\nwant\n%s", data, want)
+	}
+}
+
+// copyFile copies a testdata file to a temporary directory
+func copyFile(t *testing.T, src string) string {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), filepath.Base(src))
+	if err := os.WriteFile(dst, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func compareGolden(t *testing.T, file, golden string) {
+	t.Helper()
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s =\n%s\nwant\n%s", file, got, want)
+	}
+}
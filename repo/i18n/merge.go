@@ -0,0 +1,212 @@
+package i18n
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/kalifun/glitch/repo/gerr"
+	"gopkg.in/yaml.v3"
+)
+
+// itemStart matches the first line of an item in the error sequence
+var itemStart = regexp.MustCompile(`^\s*- `)
+
+// MergeResult reports the translations written to one YAML file
+type MergeResult struct {
+	File    string
+	Updated int
+}
+
+// Merge writes the translations of the catalogs into the YAML definition
+// files, matching entries by error code. Comments and the order of keys
+// are preserved; empty translations are skipped. Only changed files are
+// rewritten.
+func Merge(files []string, catalogs []Catalog) ([]MergeResult, error) {
+	// code -> language -> entries
+	translations := make(map[string]map[string][]Entry)
+	for _, c := range catalogs {
+		for _, e := range c.Entries {
+			if e.Target == "" || e.Code == "" {
+				continue
+			}
+			if translations[e.Code] == nil {
+				translations[e.Code] = make(map[string][]Entry)
+			}
+			translations[e.Code][c.Language] = append(translations[e.Code][c.Language], e)
+		}
+	}
+
+	var results []MergeResult
+	for _, file := range files {
+		updated, err := mergeFile(file, translations)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, MergeResult{File: file, Updated: updated})
+	}
+	return results, nil
+}
+
+func mergeFile(file string, translations map[string]map[string][]Entry) (int, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("%s: %v", file, err)
+	}
+	if len(doc.Content) == 0 {
+		return 0, nil
+	}
+
+	items := mappingValue(doc.Content[0], "error")
+	if items == nil || items.Kind != yaml.SequenceNode {
+		return 0, nil
+	}
+
+	updated := 0
+	for _, item := range items.Content {
+		code := mappingValue(item, "code")
+		if code == nil {
+			continue
+		}
+		for lang, entries := range translations[code.Value] {
+			for _, e := range entries {
+				if setMessage(item, lang, e) {
+					updated++
+				}
+			}
+		}
+	}
+	if updated == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return 0, err
+	}
+	if err := encoder.Close(); err != nil {
+		return 0, err
+	}
+
+	out := buf.Bytes()
+	if bytes.Contains(data, []byte("\n\n")) {
+		out = separateItems(out)
+	}
+	return updated, os.WriteFile(file, out, 0o644)
+}
+
+// setMessage writes the translation of e for lang into an item mapping and
+// reports whether anything changed
+func setMessage(item *yaml.Node, lang string, e Entry) bool {
+	message := mappingValue(item, "message")
+	if message == nil {
+		message = &yaml.Node{Kind: yaml.MappingNode}
+		item.Content = append(item.Content, scalar("message", 0), message)
+	}
+
+	current := mappingValue(message, lang)
+	if e.Form == "" {
+		if current == nil {
+			message.Content = append(message.Content, scalar(lang, 0), scalar(e.Target, yaml.DoubleQuotedStyle))
+			return true
+		}
+		if current.Kind == yaml.MappingNode {
+			return setForm(current, gerr.PluralOther, e.Target)
+		}
+		if current.Value == e.Target {
+			return false
+		}
+		current.Value = e.Target
+		return true
+	}
+
+	if current == nil {
+		current = &yaml.Node{Kind: yaml.MappingNode}
+		message.Content = append(message.Content, scalar(lang, 0), current)
+	} else if current.Kind == yaml.ScalarNode {
+		if e.Form == gerr.PluralOther {
+			// a plain message already is the "other" form
+			if current.Value == e.Target {
+				return false
+			}
+			current.Value = e.Target
+			return true
+		}
+		// turn a plain message into plural forms, keeping it as "other"
+		other := *current
+		*current = yaml.Node{Kind: yaml.MappingNode}
+		current.Content = append(current.Content, scalar(gerr.PluralOther, 0), &other)
+	}
+	return setForm(current, e.Form, e.Target)
+}
+
+// setForm sets one plural form and reports whether anything changed
+func setForm(forms *yaml.Node, form, text string) bool {
+	if node := mappingValue(forms, form); node != nil {
+		if node.Value == text {
+			return false
+		}
+		node.Value = text
+		return true
+	}
+	forms.Content = append(forms.Content, scalar(form, 0), scalar(text, yaml.DoubleQuotedStyle))
+	return true
+}
+
+// mappingValue returns the value of key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(value string, style yaml.Style) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, Style: style}
+}
+
+// separateItems restores the blank line between items of the error
+// sequence, which the YAML encoder drops
+func separateItems(data []byte) []byte {
+	lines := bytes.Split(data, []byte("\n"))
+	var out [][]byte
+	indent := -1
+	for _, line := range lines {
+		if itemStart.Match(line) {
+			lineIndent := len(line) - len(bytes.TrimLeft(line, " "))
+			if indent == -1 {
+				indent = lineIndent
+			} else if lineIndent == indent {
+				// the blank line goes above the comments of the item
+				at := len(out)
+				for at > 0 && isComment(out[at-1], indent) {
+					at--
+				}
+				if at > 0 && len(out[at-1]) > 0 {
+					out = append(out[:at], append([][]byte{nil}, out[at:]...)...)
+				}
+			}
+		}
+		out = append(out, line)
+	}
+	return bytes.Join(out, []byte("\n"))
+}
+
+// isComment reports whether line is a comment indented like the items
+func isComment(line []byte, indent int) bool {
+	trimmed := bytes.TrimLeft(line, " ")
+	return len(line)-len(trimmed) == indent && bytes.HasPrefix(trimmed, []byte("#"))
+}
@@ -0,0 +1,134 @@
+package i18n
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WritePO writes the catalog as a gettext PO file. The error code is the
+// message context and the description a translator comment.
+func WritePO(w io.Writer, c Catalog) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, `msgid ""`)
+	fmt.Fprintln(bw, `msgstr ""`)
+	fmt.Fprintln(bw, poQuote("Content-Type: text/plain; charset=UTF-8\n"))
+	fmt.Fprintln(bw, poQuote("Language: "+c.Language+"\n"))
+	fmt.Fprintln(bw, poQuote("X-Source-Language: "+c.SourceLanguage+"\n"))
+
+	for _, e := range c.Entries {
+		fmt.Fprintln(bw)
+		if e.Description != "" {
+			for _, line := range strings.Split(e.Description, "\n") {
+				fmt.Fprintf(bw, "#. %s\n", line)
+			}
+		}
+		fmt.Fprintf(bw, "#: %s\n", e.Key)
+		fmt.Fprintf(bw, "msgctxt %s\n", poQuote(e.Context()))
+		fmt.Fprintf(bw, "msgid %s\n", poQuote(e.Source))
+		fmt.Fprintf(bw, "msgstr %s\n", poQuote(e.Target))
+	}
+	return bw.Flush()
+}
+
+// ReadPO reads a gettext PO file written by WritePO or edited by a
+// translation tool. Fuzzy entries are skipped.
+func ReadPO(r io.Reader) (Catalog, error) {
+	var c Catalog
+	var e Entry
+	var fuzzy bool
+	var field *string
+	var ctx string
+	var hasEntry bool
+	header := ""
+
+	flush := func() {
+		if hasEntry {
+			if e.Source == "" && ctx == "" {
+				header = e.Target
+			} else if !fuzzy {
+				e.Code, e.Form = parseContext(ctx)
+				c.Entries = append(c.Entries, e)
+			}
+		}
+		e, ctx, fuzzy, field, hasEntry = Entry{}, "", false, nil, false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#,"):
+			if strings.Contains(line, "fuzzy") {
+				fuzzy = true
+			}
+		case strings.HasPrefix(line, "#."):
+			desc := strings.TrimSpace(strings.TrimPrefix(line, "#."))
+			if e.Description != "" {
+				desc = e.Description + "\n" + desc
+			}
+			e.Description = desc
+		case strings.HasPrefix(line, "#:"):
+			e.Key = strings.TrimSpace(strings.TrimPrefix(line, "#:"))
+		case strings.HasPrefix(line, "#"):
+			// other comments are ignored
+		case strings.HasPrefix(line, "msgctxt "):
+			if hasEntry && field != &ctx {
+				flush()
+			}
+			field = &ctx
+		case strings.HasPrefix(line, "msgid "):
+			field = &e.Source
+		case strings.HasPrefix(line, "msgstr "):
+			field = &e.Target
+		case strings.HasPrefix(line, `"`):
+			if field == nil {
+				return c, fmt.Errorf("po line %d: string without keyword", lineNo)
+			}
+		default:
+			return c, fmt.Errorf("po line %d: unsupported syntax %q", lineNo, line)
+		}
+
+		if i := strings.IndexByte(line, '"'); i >= 0 && !strings.HasPrefix(line, "#") {
+			s, err := strconv.Unquote(line[i:])
+			if err != nil {
+				return c, fmt.Errorf("po line %d: %v", lineNo, err)
+			}
+			if field != nil {
+				*field += s
+			}
+			hasEntry = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return c, err
+	}
+	flush()
+
+	for _, line := range strings.Split(header, "\n") {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "Language":
+			c.Language = strings.TrimSpace(value)
+		case "X-Source-Language":
+			c.SourceLanguage = strings.TrimSpace(value)
+		}
+	}
+	return c, nil
+}
+
+// poQuote quotes s as a PO string
+func poQuote(s string) string {
+	return strconv.Quote(s)
+}
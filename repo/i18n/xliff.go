@@ -0,0 +1,103 @@
+package i18n
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// xliffNamespace is the XLIFF 1.2 namespace
+const xliffNamespace = "urn:oasis:names:tc:xliff:document:1.2"
+
+type xliffDoc struct {
+	XMLName xml.Name  `xml:"xliff"`
+	Version string    `xml:"version,attr"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	File    xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	Original       string      `xml:"original,attr"`
+	SourceLanguage string      `xml:"source-language,attr"`
+	TargetLanguage string      `xml:"target-language,attr"`
+	Datatype       string      `xml:"datatype,attr"`
+	Units          []xliffUnit `xml:"body>trans-unit"`
+}
+
+type xliffUnit struct {
+	ID      string       `xml:"id,attr"`
+	Resname string       `xml:"resname,attr,omitempty"`
+	Source  string       `xml:"source"`
+	Target  *xliffTarget `xml:"target"`
+	Note    string       `xml:"note,omitempty"`
+}
+
+type xliffTarget struct {
+	State string `xml:"state,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+// WriteXLIFF writes the catalog as an XLIFF 1.2 document. The error code is
+// the unit id and the description a note for translators.
+func WriteXLIFF(w io.Writer, c Catalog) error {
+	doc := xliffDoc{
+		Version: "1.2",
+		Xmlns:   xliffNamespace,
+		File: xliffFile{
+			Original:       "glitch",
+			SourceLanguage: c.SourceLanguage,
+			TargetLanguage: c.Language,
+			Datatype:       "plaintext",
+		},
+	}
+	for _, e := range c.Entries {
+		unit := xliffUnit{
+			ID:      e.Context(),
+			Resname: e.Key,
+			Source:  e.Source,
+			Note:    e.Description,
+			Target:  &xliffTarget{Text: e.Target, State: "translated"},
+		}
+		if e.Target == "" {
+			unit.Target.State = "needs-translation"
+		}
+		doc.File.Units = append(doc.File.Units, unit)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadXLIFF reads an XLIFF 1.2 document written by WriteXLIFF or edited by
+// a translation tool
+func ReadXLIFF(r io.Reader) (Catalog, error) {
+	var doc xliffDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return Catalog{}, err
+	}
+
+	c := Catalog{
+		SourceLanguage: doc.File.SourceLanguage,
+		Language:       doc.File.TargetLanguage,
+	}
+	for _, unit := range doc.File.Units {
+		e := Entry{
+			Key:         unit.Resname,
+			Description: unit.Note,
+			Source:      unit.Source,
+		}
+		e.Code, e.Form = parseContext(unit.ID)
+		if unit.Target != nil {
+			e.Target = unit.Target.Text
+		}
+		c.Entries = append(c.Entries, e)
+	}
+	return c, nil
+}